@@ -0,0 +1,109 @@
+package main
+
+import (
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/ponyo877/suika-shaker/internal/achievement"
+	"github.com/ponyo877/suika-shaker/internal/i18n"
+	"github.com/ponyo877/suika-shaker/internal/physics"
+	"github.com/ponyo877/suika-shaker/internal/ui"
+)
+
+const (
+	// toastDuration is how long an unlock stays on screen, the last
+	// toastFade of it fading out.
+	toastDuration = 3 * time.Second
+	toastFade     = 500 * time.Millisecond
+)
+
+// toast is an unlock waiting to be announced, or being announced once
+// shownAt is set.
+type toast struct {
+	text    string
+	shownAt time.Time
+}
+
+func (g *Game) checkAchievements() {
+	g.announce(g.achievements.Check(g.state, g.sim.Mode))
+}
+
+func (g *Game) finishAchievements() {
+	g.announce(g.achievements.FinishGame(g.state, g.sim.Mode))
+}
+
+// leaveRound adds a round the player quits before it is over, which is every
+// round of Zen, to the totals as if it had finished.
+func (g *Game) leaveRound() {
+	if !g.state.GameOver && g.state.Count > 0 {
+		g.finishAchievements()
+	}
+}
+
+func (g *Game) announce(unlocked []achievement.Achievement) {
+	for _, a := range unlocked {
+		g.toasts = append(g.toasts, toast{text: i18n.T(a.Label)})
+	}
+}
+
+// drawToast shows the oldest pending unlock over whatever screen is up.
+func (g *Game) drawToast(screen *ebiten.Image) {
+	if len(g.toasts) == 0 {
+		return
+	}
+	t := &g.toasts[0]
+	if t.shownAt.IsZero() {
+		t.shownAt = time.Now()
+	}
+	elapsed := time.Since(t.shownAt)
+	if elapsed >= toastDuration {
+		g.toasts = g.toasts[1:]
+		return
+	}
+
+	alpha := 1.0
+	if left := toastDuration - elapsed; left < toastFade {
+		alpha = float64(left) / float64(toastFade)
+	}
+	g.renderer.DrawToast(screen, i18n.T("achievement_unlocked"), t.text, alpha)
+}
+
+func (g *Game) openAchievements() {
+	g.state.ShowTitleScreen = false
+	g.state.ShowAchievements = true
+	hideShareButton()
+}
+
+func (g *Game) handleAchievementsInput() {
+	if clicked, x, y := g.inputHandler.CheckMouseClick(); clicked {
+		g.handleAchievementsClick(x, y)
+	}
+	for _, touch := range g.inputHandler.CheckTouchInput() {
+		g.handleAchievementsClick(touch.X, touch.Y)
+	}
+}
+
+func (g *Game) handleAchievementsClick(x, y int) {
+	if g.inputHandler.IsButtonClicked(x, y, ui.HomeButtonConfig) {
+		g.returnToTitle()
+	}
+}
+
+func (g *Game) drawAchievements(screen *ebiten.Image) {
+	all := achievement.All()
+	rows := make([]ui.AchievementRow, len(all))
+	unlocked := 0
+	for i, a := range all {
+		rows[i] = ui.AchievementRow{
+			Label:    i18n.T(a.Label),
+			Detail:   i18n.T(a.Detail),
+			Unlocked: g.achievements.Has(a.ID),
+		}
+		if rows[i].Unlocked {
+			unlocked++
+		}
+	}
+	subtitle := i18n.Tf("achievements_total", unlocked, len(all))
+	g.renderer.DrawAchievements(screen, i18n.T("achievements"), subtitle, rows, physics.PaddingBottom)
+	g.renderer.DrawHomeButton(screen)
+}
@@ -3,11 +3,11 @@ package assets
 import (
 	"bytes"
 	_ "embed"
+	"fmt"
 	"image"
 	_ "golang.org/x/image/webp"
 	"log"
 
-	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/jakecoffman/cp/v2"
 )
 
@@ -53,7 +53,7 @@ var (
 	titlelogoWebP []byte
 
 	assets map[Kind]ImageSet
-	icons  map[IconKind]*ebiten.Image
+	icons  map[IconKind]image.Image
 )
 
 type IconKind int
@@ -67,6 +67,70 @@ const (
 
 type Kind int
 
+var kindNames = map[Kind]string{
+	Grape:      "grape",
+	Mandarin:   "mandarin",
+	Apple:      "apple",
+	Pear:       "pear",
+	Peach:      "peach",
+	Pineapple:  "pineapple",
+	Melon:      "melon",
+	Watermelon: "watermelon",
+	Bomb:       "bomb",
+	Rainbow:    "rainbow",
+	Freeze:     "freeze",
+	Shrink:     "shrink",
+}
+
+var iconNames = map[IconKind]string{
+	Speaker:   "speaker",
+	Muted:     "muted",
+	Share:     "share",
+	TitleLogo: "titlelogo",
+}
+
+func (k Kind) String() string {
+	if name, ok := kindNames[k]; ok {
+		return name
+	}
+	return fmt.Sprintf("kind(%d)", int(k))
+}
+
+func ParseKind(name string) (Kind, bool) {
+	for k, n := range kindNames {
+		if n == name {
+			return k, true
+		}
+	}
+	return 0, false
+}
+
+// MarshalText and UnmarshalText let data files refer to fruits by name.
+func (k Kind) MarshalText() ([]byte, error) {
+	if _, ok := kindNames[k]; !ok {
+		return nil, fmt.Errorf("unknown fruit kind %d", int(k))
+	}
+	return []byte(k.String()), nil
+}
+
+func (k *Kind) UnmarshalText(text []byte) error {
+	kind, ok := ParseKind(string(text))
+	if !ok {
+		return fmt.Errorf("unknown fruit %q", text)
+	}
+	*k = kind
+	return nil
+}
+
+func ParseIconKind(name string) (IconKind, bool) {
+	for k, n := range iconNames {
+		if n == name {
+			return k, true
+		}
+	}
+	return 0, false
+}
+
 func (k Kind) Next() (hasNext bool, next Kind) {
 	if k < Max {
 		return true, k + 1
@@ -79,53 +143,41 @@ func (k Kind) Score() int {
 }
 
 type ImageSet struct {
-	EbitenImage *ebiten.Image
-	Image       image.Image
-	Scale       float64
-	Vectors     []cp.Vector
-	Score       int
+	Image   image.Image
+	Scale   float64
+	Vectors []cp.Vector
+	Score   int
 }
 
 func init() {
-	grapeImg, grapeEbiten := decodeImage(grapeWebP)
-	mandarinImg, mandarinEbiten := decodeImage(mandarinWebP)
-	appleImg, appleEbiten := decodeImage(appleWebP)
-	pearImg, pearEbiten := decodeImage(pearWebP)
-	peachImg, peachEbiten := decodeImage(peachWebP)
-	pineappleImg, pineappleEbiten := decodeImage(pineappleWebP)
-	melonImg, melonEbiten := decodeImage(melonWebP)
-	watermelonImg, watermelonEbiten := decodeImage(watermelonWebP)
-
 	assets = map[Kind]ImageSet{
-		Grape:      newImageSet(grapeImg, grapeEbiten, 1.0, 10),
-		Mandarin:   newImageSet(mandarinImg, mandarinEbiten, 1.0, 20),
-		Apple:      newImageSet(appleImg, appleEbiten, 1.0, 60),
-		Pear:       newImageSet(pearImg, pearEbiten, 1.0, 70),
-		Peach:      newImageSet(peachImg, peachEbiten, 1.0, 80),
-		Pineapple:  newImageSet(pineappleImg, pineappleEbiten, 1.0, 90),
-		Melon:      newImageSet(melonImg, melonEbiten, 1.0, 100),
-		Watermelon: newImageSet(watermelonImg, watermelonEbiten, 1.0, 110),
+		Grape:      newImageSet(decodeImage(grapeWebP), 1.0, 10),
+		Mandarin:   newImageSet(decodeImage(mandarinWebP), 1.0, 20),
+		Apple:      newImageSet(decodeImage(appleWebP), 1.0, 60),
+		Pear:       newImageSet(decodeImage(pearWebP), 1.0, 70),
+		Peach:      newImageSet(decodeImage(peachWebP), 1.0, 80),
+		Pineapple:  newImageSet(decodeImage(pineappleWebP), 1.0, 90),
+		Melon:      newImageSet(decodeImage(melonWebP), 1.0, 100),
+		Watermelon: newImageSet(decodeImage(watermelonWebP), 1.0, 110),
+	}
+	for kind, shade := range specialShades {
+		assets[kind] = newImageSet(specialImage(shade), 1.0, 0)
 	}
 
-	_, speakerEbiten := decodeImage(speakerWebP)
-	_, mutedEbiten := decodeImage(mutedWebP)
-	_, shareEbiten := decodeImage(shareWebP)
-	_, titleLogoEbiten := decodeImage(titlelogoWebP)
-
-	icons = map[IconKind]*ebiten.Image{
-		Speaker:   speakerEbiten,
-		Muted:     mutedEbiten,
-		Share:     shareEbiten,
-		TitleLogo: titleLogoEbiten,
+	icons = map[IconKind]image.Image{
+		Speaker:   decodeImage(speakerWebP),
+		Muted:     decodeImage(mutedWebP),
+		Share:     decodeImage(shareWebP),
+		TitleLogo: decodeImage(titlelogoWebP),
 	}
 }
 
-func decodeImage(data []byte) (image.Image, *ebiten.Image) {
+func decodeImage(data []byte) image.Image {
 	img, _, err := image.Decode(bytes.NewReader(data))
 	if err != nil {
 		log.Fatal(err)
 	}
-	return img, ebiten.NewImageFromImage(img)
+	return img
 }
 
 func Get(kind Kind) ImageSet {
@@ -136,7 +188,7 @@ func Get(kind Kind) ImageSet {
 	return imageSet
 }
 
-func GetIcon(kind IconKind) *ebiten.Image {
+func GetIcon(kind IconKind) image.Image {
 	icon, ok := icons[kind]
 	if !ok {
 		log.Fatalf("icon %d not found", kind)
@@ -144,12 +196,30 @@ func GetIcon(kind IconKind) *ebiten.Image {
 	return icon
 }
 
+// SetImage replaces a kind's artwork, keeping its score: scores come from
+// the table in init alone, so that every client scores a round alike.
+func SetImage(kind Kind, img image.Image, scale float64) {
+	assets[kind] = newImageSet(img, scale, assets[kind].Score)
+}
+
+// SetScore changes what merging into kind is worth. It is for tools that
+// tune the table; the game itself never calls it.
+func SetScore(kind Kind, score int) {
+	set := assets[kind]
+	set.Score = score
+	assets[kind] = set
+}
+
+func SetIcon(kind IconKind, img image.Image) {
+	icons[kind] = img
+}
+
 func Length() int {
 	return len(assets)
 }
 
 func Exists(kind Kind) bool {
-	return kind >= Min && kind <= Max
+	return kind >= Min && kind <= Max || kind.Special()
 }
 
 func ForEach(fn func(Kind, ImageSet)) {
@@ -158,13 +228,12 @@ func ForEach(fn func(Kind, ImageSet)) {
 	}
 }
 
-func newImageSet(img image.Image, ebitenImg *ebiten.Image, scale float64, score int) ImageSet {
+func newImageSet(img image.Image, scale float64, score int) ImageSet {
 	return ImageSet{
-		EbitenImage: ebitenImg,
-		Image:       img,
-		Scale:       scale,
-		Vectors:     generateVectors(img, scale),
-		Score:       score,
+		Image:   img,
+		Scale:   scale,
+		Vectors: generateVectors(img, scale),
+		Score:   score,
 	}
 }
 
@@ -0,0 +1,83 @@
+package assets
+
+import (
+	"image"
+	"image/color"
+	"math"
+)
+
+// Power-ups drop like fruits but never merge into the next kind; each has
+// its own effect when it first touches something.
+const (
+	Bomb Kind = Max + 1 + iota
+	Rainbow
+	Freeze
+	Shrink
+
+	SpecialMin Kind = Bomb
+	SpecialMax Kind = Shrink
+)
+
+const specialSize = 64
+
+// Special reports whether k is a power-up rather than a fruit.
+func (k Kind) Special() bool {
+	return k >= SpecialMin && k <= SpecialMax
+}
+
+// specialImage draws a power-up's token, since they ship without artwork: a
+// disc whose colour at each pixel comes from shade, given the offset from the
+// centre in units of the radius.
+func specialImage(shade func(x, y float64) color.NRGBA) image.Image {
+	const radius = specialSize/2 - 2
+	img := image.NewNRGBA(image.Rect(0, 0, specialSize, specialSize))
+	for py := range specialSize {
+		for px := range specialSize {
+			x := (float64(px) + 0.5 - specialSize/2) / radius
+			y := (float64(py) + 0.5 - specialSize/2) / radius
+			if x*x+y*y <= 1 {
+				img.SetNRGBA(px, py, shade(x, y))
+			}
+		}
+	}
+	return img
+}
+
+var specialShades = map[Kind]func(x, y float64) color.NRGBA{
+	// A black ball with a highlight and a lit fuse.
+	Bomb: func(x, y float64) color.NRGBA {
+		switch {
+		case math.Abs(x) < 0.12 && y < -0.55:
+			return color.NRGBA{255, 160, 40, 255}
+		case (x+0.35)*(x+0.35)+(y+0.35)*(y+0.35) < 0.04:
+			return color.NRGBA{150, 150, 160, 255}
+		}
+		return color.NRGBA{40, 40, 48, 255}
+	},
+	// Bands of colour round the centre.
+	Rainbow: func(x, y float64) color.NRGBA {
+		bands := []color.NRGBA{
+			{230, 70, 70, 255}, {240, 160, 50, 255}, {240, 220, 70, 255},
+			{80, 190, 90, 255}, {70, 140, 230, 255}, {150, 90, 210, 255},
+		}
+		return bands[int(math.Hypot(x, y)*float64(len(bands)))%len(bands)]
+	},
+	// Ice blue with a white six-armed flake.
+	Freeze: func(x, y float64) color.NRGBA {
+		r, a := math.Hypot(x, y), math.Atan2(y, x)
+		arm := math.Abs(math.Sin(3 * a))
+		if r < 0.8 && arm*r < 0.09 {
+			return color.NRGBA{255, 255, 255, 255}
+		}
+		return color.NRGBA{120, 200, 240, 255}
+	},
+	// Violet with a white arrow pointing down.
+	Shrink: func(x, y float64) color.NRGBA {
+		shaft := math.Abs(x) < 0.15 && y > -0.6 && y < 0.15
+		head := y >= 0.15 && y < 0.65 && math.Abs(x) < 0.65-y
+		if shaft || head {
+			return color.NRGBA{255, 255, 255, 255}
+		}
+		return color.NRGBA{150, 90, 200, 255}
+	},
+}
@@ -3,8 +3,11 @@ package sound
 import (
 	"bytes"
 	_ "embed"
+	"encoding/binary"
+	"fmt"
 	"io"
 	"log"
+	"math"
 
 	"github.com/hajimehoshi/ebiten/v2/audio"
 	"github.com/hajimehoshi/ebiten/v2/audio/vorbis"
@@ -12,6 +15,16 @@ import (
 
 const sampleRate = 48000
 
+type Kind int
+
+const (
+	Background Kind = iota
+	GameOver
+	Join
+	SuikaJoin
+	Warning
+)
+
 var (
 	//go:embed background.ogg
 	backgroundOGG []byte
@@ -29,6 +42,7 @@ type Manager struct {
 	gameoverData     []byte
 	joinData         []byte
 	suikajoinData    []byte
+	warningData      []byte
 	muted            bool
 }
 
@@ -59,20 +73,91 @@ func NewManager() *Manager {
 		gameoverData:     decodeToBytes(gameoverOGG),
 		joinData:         decodeToBytes(joinOGG),
 		suikajoinData:    decodeToBytes(suikajoinOGG),
+		warningData:      warningTone(),
 		muted:            false,
 	}
 }
 
+// warningTone synthesises the danger cue, a falling two-note beep, as stereo
+// 32-bit float samples so that no recording has to ship with the game.
+func warningTone() []byte {
+	const (
+		noteLength = sampleRate / 8
+		volume     = 0.25
+	)
+	var buf bytes.Buffer
+	for _, freq := range []float64{880, 660} {
+		for i := range noteLength {
+			t := float64(i) / sampleRate
+			// Fade each note in and out to avoid clicks.
+			envelope := math.Sin(math.Pi * float64(i) / noteLength)
+			v := float32(volume * envelope * math.Sin(2*math.Pi*freq*t))
+			binary.Write(&buf, binary.LittleEndian, [2]float32{v, v})
+		}
+	}
+	return buf.Bytes()
+}
+
 func decodeToBytes(oggData []byte) []byte {
-	stream, err := vorbis.DecodeF32(bytes.NewReader(oggData))
+	data, err := decode(oggData)
 	if err != nil {
 		log.Fatal(err)
 	}
-	data, err := io.ReadAll(stream)
+	return data
+}
+
+func decode(oggData []byte) ([]byte, error) {
+	stream, err := vorbis.DecodeF32(bytes.NewReader(oggData))
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
-	return data
+	return io.ReadAll(stream)
+}
+
+func (m *Manager) Replace(kind Kind, oggData []byte) error {
+	if kind == Background {
+		return m.replaceBackground(oggData)
+	}
+
+	data, err := decode(oggData)
+	if err != nil {
+		return err
+	}
+
+	switch kind {
+	case GameOver:
+		m.gameoverData = data
+	case Join:
+		m.joinData = data
+	case SuikaJoin:
+		m.suikajoinData = data
+	case Warning:
+		m.warningData = data
+	default:
+		return fmt.Errorf("unknown sound kind %d", kind)
+	}
+	return nil
+}
+
+func (m *Manager) replaceBackground(oggData []byte) error {
+	stream, err := vorbis.DecodeF32(bytes.NewReader(oggData))
+	if err != nil {
+		return err
+	}
+
+	player, err := m.context.NewPlayerF32(audio.NewInfiniteLoop(stream, stream.Length()))
+	if err != nil {
+		return err
+	}
+	player.SetVolume(0.3)
+
+	wasPlaying := m.backgroundPlayer != nil && m.backgroundPlayer.IsPlaying()
+	m.StopBackgroundMusic()
+	m.backgroundPlayer = player
+	if wasPlaying {
+		m.StartBackgroundMusic()
+	}
+	return nil
 }
 
 func (m *Manager) SetMuted(muted bool) {
@@ -112,6 +197,14 @@ func (m *Manager) PlaySuikaJoin() {
 	player.Play()
 }
 
+func (m *Manager) PlayWarning() {
+	if m.muted {
+		return
+	}
+	player := m.context.NewPlayerF32FromBytes(m.warningData)
+	player.Play()
+}
+
 func (m *Manager) StartBackgroundMusic() {
 	if m.backgroundPlayer != nil && !m.backgroundPlayer.IsPlaying() {
 		m.backgroundPlayer.Play()
@@ -128,6 +221,10 @@ func SetMuted(muted bool) {
 	defaultManager.SetMuted(muted)
 }
 
+func Replace(kind Kind, oggData []byte) error {
+	return defaultManager.Replace(kind, oggData)
+}
+
 func PlayGameOver() {
 	defaultManager.PlayGameOver()
 }
@@ -140,6 +237,10 @@ func PlaySuikaJoin() {
 	defaultManager.PlaySuikaJoin()
 }
 
+func PlayWarning() {
+	defaultManager.PlayWarning()
+}
+
 func StartBackgroundMusic() {
 	defaultManager.StartBackgroundMusic()
 }
@@ -0,0 +1,266 @@
+// Command balance plays simulated rounds across a grid of tuning parameters
+// and writes, for every combination, the average score, round length and
+// how often a watermelon was made, as CSV or JSON.
+//
+// Each list flag adds a dimension to the grid; leave it empty to keep the
+// mode's own value. For example
+//
+//	balance -drop-interval 30,45,60 -spawn-weights "1:1;3:2:1" -gravity 1,1.5
+//
+// plays 3 x 2 x 2 = 12 combinations.
+//
+// A swept drop interval holds for the whole round, replacing the mode's
+// curve that shortens it as the round goes on, so the drop_interval column
+// is what was played. Without -drop-interval the mode's curve is kept and
+// the column shows the interval it starts from.
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"io"
+	"log"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+
+	assets "github.com/ponyo877/suika-shaker/assets/image"
+	"github.com/ponyo877/suika-shaker/internal/bot"
+	"github.com/ponyo877/suika-shaker/internal/mode"
+	"github.com/ponyo877/suika-shaker/internal/sim"
+)
+
+var (
+	modeName      = flag.String("mode", mode.Classic, "game mode the grid starts from")
+	policies      = flag.String("policy", "greedy", "comma-separated input policies: "+strings.Join(bot.Policies, ", "))
+	dropIntervals = flag.String("drop-interval", "", "comma-separated ticks between drops")
+	spawnWeights  = flag.String("spawn-weights", "", "semicolon-separated spawn tables, each colon-separated weights from the grape up, e.g. 1:1;3:2:1")
+	scoreTables   = flag.String("scores", "", "semicolon-separated fruit score tables, each colon-separated points from the grape up")
+	gravityScales = flag.String("gravity", "", "comma-separated gravity multipliers")
+	rounds        = flag.Int("rounds", 50, "rounds per combination, one seed each")
+	firstSeed     = flag.Int64("seed", 1, "seed of the first round of every combination")
+	maxSeconds    = flag.Int("max-seconds", 300, "stop a round that has not ended after this many simulated seconds")
+	horizon       = flag.Int("horizon", 45, "ticks the lookahead policy simulates per action")
+	replan        = flag.Int("replan", 15, "ticks the lookahead policy holds a choice before searching again")
+	workers       = flag.Int("workers", runtime.NumCPU(), "rounds played in parallel")
+	format        = flag.String("format", "csv", "output format: csv or json")
+	outPath       = flag.String("out", "", "output file; empty writes to stdout")
+)
+
+// row is one combination of parameters and what the rounds played with it
+// averaged.
+type row struct {
+	Policy       string  `json:"policy"`
+	DropInterval int     `json:"drop_interval"`
+	SpawnWeights []int   `json:"spawn_weights,omitempty"`
+	Scores       []int   `json:"scores,omitempty"`
+	GravityScale float64 `json:"gravity_scale"`
+	bot.Summary
+}
+
+var csvHeader = []string{
+	"policy", "drop_interval", "spawn_weights", "scores", "gravity_scale",
+	"rounds", "mean_score", "sd_score", "median_score", "p10_score", "p90_score",
+	"mean_seconds", "survived", "watermelon_rate",
+}
+
+func (r row) csv() []string {
+	return []string{
+		r.Policy,
+		strconv.Itoa(r.DropInterval),
+		joinInts(r.SpawnWeights),
+		joinInts(r.Scores),
+		strconv.FormatFloat(r.GravityScale, 'g', -1, 64),
+		strconv.Itoa(r.Rounds),
+		strconv.FormatFloat(r.Mean, 'f', 1, 64),
+		strconv.FormatFloat(r.StdDev, 'f', 1, 64),
+		strconv.Itoa(r.Median),
+		strconv.Itoa(r.P10),
+		strconv.Itoa(r.P90),
+		strconv.FormatFloat(r.MeanTime, 'f', 1, 64),
+		strconv.FormatFloat(r.Survived, 'f', 3, 64),
+		strconv.FormatFloat(r.WatermelonRate, 'f', 3, 64),
+	}
+}
+
+func joinInts(values []int) string {
+	parts := make([]string, len(values))
+	for i, v := range values {
+		parts[i] = strconv.Itoa(v)
+	}
+	return strings.Join(parts, ":")
+}
+
+func parseInts(s, sep string) ([]int, error) {
+	var values []int
+	for _, part := range strings.Split(s, sep) {
+		v, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+	}
+	return values, nil
+}
+
+// parseTables reads semicolon-separated tables of colon-separated integers.
+// An empty flag yields a single nil table, meaning "keep the default".
+func parseTables(s string) ([][]int, error) {
+	if s == "" {
+		return [][]int{nil}, nil
+	}
+	var tables [][]int
+	for _, table := range strings.Split(s, ";") {
+		values, err := parseInts(table, ":")
+		if err != nil {
+			return nil, err
+		}
+		tables = append(tables, values)
+	}
+	return tables, nil
+}
+
+func parseFloats(s string) ([]float64, error) {
+	var values []float64
+	for _, part := range strings.Split(s, ",") {
+		v, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+	}
+	return values, nil
+}
+
+// applyScores sets the fruit score table, which the game keeps globally, so
+// combinations are played one score table at a time. A nil table restores
+// the scores the game started with.
+func applyScores(scores []int, defaults map[assets.Kind]assets.ImageSet) {
+	for kind, set := range defaults {
+		score := set.Score
+		if i := int(kind - assets.Min); i < len(scores) {
+			score = scores[i]
+		}
+		assets.SetScore(kind, score)
+	}
+}
+
+func main() {
+	flag.Parse()
+
+	base, ok := mode.Lookup(*modeName)
+	if !ok {
+		log.Fatalf("unknown mode %q", *modeName)
+	}
+	if *rounds <= 0 {
+		log.Fatal("-rounds must be positive")
+	}
+	if *format != "csv" && *format != "json" {
+		log.Fatalf("unknown -format %q, want csv or json", *format)
+	}
+
+	names := strings.Split(*policies, ",")
+	for _, name := range names {
+		if _, err := bot.NewPolicy(name, *horizon, *replan); err != nil {
+			log.Fatal(err)
+		}
+	}
+	intervals := []int{base.DropInterval}
+	if *dropIntervals != "" {
+		var err error
+		if intervals, err = parseInts(*dropIntervals, ","); err != nil {
+			log.Fatalf("invalid -drop-interval: %v", err)
+		}
+	}
+	gravities := []float64{1}
+	if *gravityScales != "" {
+		var err error
+		if gravities, err = parseFloats(*gravityScales); err != nil {
+			log.Fatalf("invalid -gravity: %v", err)
+		}
+	}
+	weights, err := parseTables(*spawnWeights)
+	if err != nil {
+		log.Fatalf("invalid -spawn-weights: %v", err)
+	}
+	scores, err := parseTables(*scoreTables)
+	if err != nil {
+		log.Fatalf("invalid -scores: %v", err)
+	}
+
+	var out io.Writer = os.Stdout
+	if *outPath != "" {
+		f, err := os.Create(*outPath)
+		if err != nil {
+			log.Fatal("Failed to create output file:", err)
+		}
+		defer f.Close()
+		out = f
+	}
+
+	defaults := map[assets.Kind]assets.ImageSet{}
+	assets.ForEach(func(kind assets.Kind, set assets.ImageSet) { defaults[kind] = set })
+
+	total := len(scores) * len(intervals) * len(weights) * len(gravities) * len(names)
+	var rows []row
+	for _, table := range scores {
+		applyScores(table, defaults)
+		for _, interval := range intervals {
+			for _, w := range weights {
+				for _, gravity := range gravities {
+					for _, name := range names {
+						m := base
+						if *dropIntervals != "" {
+							// Like a swept table, a swept interval holds for
+							// the whole round.
+							m.DropInterval, m.MinDropInterval, m.RampEvery = interval, interval, 0
+						}
+						if w != nil {
+							// A swept table holds for the whole round.
+							m.SpawnWeights, m.SpawnTable = w, nil
+						}
+						m.GravityScale = gravity
+
+						newPolicy := func() bot.Policy {
+							p, _ := bot.NewPolicy(name, *horizon, *replan)
+							return p
+						}
+						results := bot.PlayMany(m, *firstSeed, *rounds, newPolicy, *maxSeconds*sim.TicksPerSecond, *workers)
+						rows = append(rows, row{
+							Policy:       name,
+							DropInterval: interval,
+							SpawnWeights: w,
+							Scores:       table,
+							GravityScale: gravity,
+							Summary:      bot.Summarize(results),
+						})
+						log.Printf("%d/%d combinations played", len(rows), total)
+					}
+				}
+			}
+		}
+	}
+
+	if err := write(out, rows); err != nil {
+		log.Fatal("Failed to write results:", err)
+	}
+}
+
+func write(out io.Writer, rows []row) error {
+	switch *format {
+	case "json":
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(rows)
+	default:
+		w := csv.NewWriter(out)
+		w.Write(csvHeader)
+		for _, r := range rows {
+			w.Write(r.csv())
+		}
+		w.Flush()
+		return w.Error()
+	}
+}
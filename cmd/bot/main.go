@@ -0,0 +1,93 @@
+// Command bot plays many headless rounds with the bot policies and reports
+// how the scores are distributed, for balancing drop rates and fruit scores.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"runtime"
+	"strings"
+
+	"github.com/ponyo877/suika-shaker/internal/bot"
+	"github.com/ponyo877/suika-shaker/internal/mode"
+	"github.com/ponyo877/suika-shaker/internal/sim"
+)
+
+var (
+	policies     = flag.String("policy", "greedy,lookahead", "comma-separated policies to compare: level, sway, greedy, lookahead")
+	modeName     = flag.String("mode", mode.Classic, "game mode to play")
+	rounds       = flag.Int("rounds", 100, "rounds per policy, one seed each")
+	firstSeed    = flag.Int64("seed", 1, "seed of the first round; later rounds count up from it")
+	maxSeconds   = flag.Int("max-seconds", 300, "stop a round that has not ended after this many simulated seconds")
+	dropInterval = flag.Int("drop-interval", 0, "override the mode's ticks between drops")
+	horizon      = flag.Int("horizon", 45, "ticks the lookahead policy simulates per action")
+	replan       = flag.Int("replan", 15, "ticks the lookahead policy holds a choice before searching again")
+	workers      = flag.Int("workers", runtime.NumCPU(), "rounds played in parallel")
+	bucket       = flag.Int("bucket", 500, "score histogram bucket width")
+	jsonOut      = flag.Bool("json", false, "print every round as a JSON line instead of a report")
+)
+
+func playAll(m mode.Mode, name string) []bot.Result {
+	newPolicy := func() bot.Policy {
+		p, _ := bot.NewPolicy(name, *horizon, *replan)
+		return p
+	}
+	return bot.PlayMany(m, *firstSeed, *rounds, newPolicy, *maxSeconds*sim.TicksPerSecond, *workers)
+}
+
+func report(name string, results []bot.Result) {
+	s := bot.Summarize(results)
+	fmt.Printf("%s: %d rounds\n", name, s.Rounds)
+	fmt.Printf("  score  mean %.0f  sd %.0f  min %d  p10 %d  median %d  p90 %d  max %d\n",
+		s.Mean, s.StdDev, s.Min, s.P10, s.Median, s.P90, s.Max)
+	fmt.Printf("  survived %.0f%%  mean length %.0fs  watermelon in %.0f%%\n", s.Survived*100, s.MeanTime, s.WatermelonRate*100)
+
+	hist := bot.Histogram(results, *bucket)
+	peak := 0
+	for _, n := range hist {
+		peak = max(peak, n)
+	}
+	for i, n := range hist {
+		fmt.Printf("  %6d-%-6d %4d %s\n", i**bucket, (i+1)**bucket-1, n, strings.Repeat("#", n*40/max(1, peak)))
+	}
+}
+
+func main() {
+	flag.Parse()
+
+	m, ok := mode.Lookup(*modeName)
+	if !ok {
+		log.Fatalf("unknown mode %q", *modeName)
+	}
+	if *dropInterval > 0 {
+		m.DropInterval = *dropInterval
+	}
+	if *rounds <= 0 {
+		log.Fatal("-rounds must be positive")
+	}
+
+	names := strings.Split(*policies, ",")
+	for _, name := range names {
+		if _, err := bot.NewPolicy(name, *horizon, *replan); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	for _, name := range names {
+		results := playAll(m, name)
+		if !*jsonOut {
+			report(name, results)
+			continue
+		}
+		for _, r := range results {
+			enc.Encode(struct {
+				Policy string `json:"policy"`
+				bot.Result
+			}{name, r})
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const subsetSuffix = "-subset.ttf"
+
+var (
+	fontDir  = flag.String("fonts", "internal/ui/fonts", "directory containing the source .ttf files")
+	fallback = flag.String("fallback", "MPlus1p-Regular.ttf", "comma-separated fonts used for runes missing from the primary fonts")
+	scan     = flag.String("scan", ".,internal/ui/...,internal/i18n/...", "comma-separated directories scanned for UI strings; dir/... includes subdirectories")
+	check    = flag.Bool("check", false, "only verify that the existing subsets cover every used rune")
+	extra    = flag.String("extra", "0123456789", "runes always included in every subset")
+)
+
+func main() {
+	flag.Parse()
+
+	used, err := usedRunes(strings.Split(*scan, ","))
+	if err != nil {
+		log.Fatal("Failed to scan UI strings:", err)
+	}
+	for _, r := range *extra {
+		if _, ok := used[r]; !ok {
+			used[r] = "-extra"
+		}
+	}
+
+	runes := make([]rune, 0, len(used))
+	for r := range used {
+		runes = append(runes, r)
+	}
+	sort.Slice(runes, func(i, j int) bool { return runes[i] < runes[j] })
+
+	fonts, err := loadFonts()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if !*check {
+		for _, f := range fonts {
+			if err := f.writeSubset(runes); err != nil {
+				log.Fatalf("Failed to subset %s: %v", f.name, err)
+			}
+		}
+		if fonts, err = loadFonts(); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	if missing := findMissing(fonts, runes); len(missing) > 0 {
+		for _, m := range missing {
+			fmt.Fprintf(os.Stderr, "%s: %q (U+%04X) used in %s\n", m.font, m.r, m.r, used[m.r])
+		}
+		log.Fatalf("%d glyph(s) missing from the embedded fonts", len(missing))
+	}
+	fmt.Printf("%d runes covered by %d font(s)\n", len(runes), len(fonts))
+}
+
+type fontFile struct {
+	name     string
+	fallback bool
+	source   *sfnt
+	subset   map[rune]uint16
+}
+
+func loadFonts() ([]*fontFile, error) {
+	isFallback := make(map[string]bool)
+	for _, name := range strings.Split(*fallback, ",") {
+		if name != "" {
+			isFallback[name] = true
+		}
+	}
+
+	paths, err := filepath.Glob(filepath.Join(*fontDir, "*.ttf"))
+	if err != nil {
+		return nil, err
+	}
+
+	var fonts []*fontFile
+	for _, path := range paths {
+		name := filepath.Base(path)
+		if strings.HasSuffix(name, subsetSuffix) {
+			continue
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		source, err := parseSFNT(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+
+		f := &fontFile{name: name, fallback: isFallback[name], source: source}
+		if f.subset, err = readRuneMap(f.subsetPath()); err != nil {
+			return nil, fmt.Errorf("%s: %w", filepath.Base(f.subsetPath()), err)
+		}
+		fonts = append(fonts, f)
+	}
+	return fonts, nil
+}
+
+func readRuneMap(path string) (map[rune]uint16, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return map[rune]uint16{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	f, err := parseSFNT(data)
+	if err != nil {
+		return nil, err
+	}
+	return f.runeMap()
+}
+
+func (f *fontFile) subsetPath() string {
+	return filepath.Join(*fontDir, strings.TrimSuffix(f.name, ".ttf")+subsetSuffix)
+}
+
+func (f *fontFile) writeSubset(runes []rune) error {
+	out, err := f.source.subset(runes)
+	if err != nil {
+		return err
+	}
+
+	data := out.encode()
+	if err := os.WriteFile(f.subsetPath(), data, 0644); err != nil {
+		return err
+	}
+	fmt.Printf("%s -> %s (%d bytes)\n", f.name, filepath.Base(f.subsetPath()), len(data))
+	return nil
+}
+
+type missingGlyph struct {
+	font string
+	r    rune
+}
+
+// findMissing reports, for every primary font, the runes that neither its
+// subset nor any fallback subset can render.
+func findMissing(fonts []*fontFile, runes []rune) []missingGlyph {
+	var missing []missingGlyph
+	for _, f := range fonts {
+		if f.fallback {
+			continue
+		}
+		for _, r := range runes {
+			if _, ok := f.subset[r]; ok {
+				continue
+			}
+			covered := false
+			for _, fb := range fonts {
+				if _, ok := fb.subset[r]; fb.fallback && ok {
+					covered = true
+					break
+				}
+			}
+			if !covered {
+				missing = append(missing, missingGlyph{font: f.name, r: r})
+			}
+		}
+	}
+	return missing
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"encoding/json"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// usedRunes collects every printable rune that can reach the screen: the
+// string literals of Go sources and the message values of JSON catalogues
+// found in roots. A root ending in /... also covers its subdirectories. Each
+// rune is mapped to the first file it was seen in.
+func usedRunes(roots []string) (map[rune]string, error) {
+	used := make(map[rune]string)
+	add := func(s, file string) {
+		for _, r := range s {
+			if unicode.IsControl(r) {
+				continue
+			}
+			if _, ok := used[r]; !ok {
+				used[r] = file
+			}
+		}
+	}
+
+	for _, root := range roots {
+		root, recursive := strings.CutSuffix(root, "/...")
+		err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				if path != root && !recursive {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			switch {
+			case strings.HasSuffix(path, "_test.go"):
+				return nil
+			case strings.HasSuffix(path, ".go"):
+				return scanGoFile(path, add)
+			case strings.HasSuffix(path, ".json"):
+				return scanJSONFile(path, add)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return used, nil
+}
+
+func scanGoFile(path string, add func(s, file string)) error {
+	file, err := parser.ParseFile(token.NewFileSet(), path, nil, parser.SkipObjectResolution)
+	if err != nil {
+		return err
+	}
+
+	ast.Inspect(file, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.ImportSpec, *ast.Field:
+			return false
+		case *ast.BasicLit:
+			if n.Kind == token.STRING {
+				if s, err := strconv.Unquote(n.Value); err == nil {
+					add(s, path)
+				}
+			}
+		}
+		return true
+	})
+	return nil
+}
+
+func scanJSONFile(path string, add func(s, file string)) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	walkJSON(v, func(s string) { add(s, path) })
+	return nil
+}
+
+func walkJSON(v interface{}, fn func(string)) {
+	switch v := v.(type) {
+	case string:
+		fn(v)
+	case []interface{}:
+		for _, e := range v {
+			walkJSON(e, fn)
+		}
+	case map[string]interface{}:
+		for _, e := range v {
+			walkJSON(e, fn)
+		}
+	}
+}
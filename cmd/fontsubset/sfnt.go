@@ -0,0 +1,415 @@
+package main
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/bits"
+	"sort"
+)
+
+// Tables that become invalid once glyph outlines are removed. GSUB could
+// substitute a kept glyph with one we dropped, so it is removed entirely.
+var droppedTables = map[string]bool{
+	"DSIG": true,
+	"GSUB": true,
+}
+
+type sfnt struct {
+	tables map[string][]byte
+}
+
+func parseSFNT(data []byte) (*sfnt, error) {
+	if len(data) < 12 {
+		return nil, errors.New("file too short")
+	}
+	if version := binary.BigEndian.Uint32(data); version != 0x00010000 && version != 0x74727565 {
+		return nil, fmt.Errorf("unsupported sfnt version %#x (only TrueType outlines are supported)", version)
+	}
+
+	numTables := int(binary.BigEndian.Uint16(data[4:]))
+	f := &sfnt{tables: make(map[string][]byte, numTables)}
+	for i := 0; i < numTables; i++ {
+		rec := data[12+16*i:]
+		tag := string(rec[:4])
+		offset := binary.BigEndian.Uint32(rec[8:])
+		length := binary.BigEndian.Uint32(rec[12:])
+		if int(offset+length) > len(data) {
+			return nil, fmt.Errorf("table %s out of range", tag)
+		}
+		f.tables[tag] = data[offset : offset+length]
+	}
+
+	for _, tag := range []string{"cmap", "glyf", "head", "loca", "maxp"} {
+		if _, ok := f.tables[tag]; !ok {
+			return nil, fmt.Errorf("missing %s table", tag)
+		}
+	}
+	return f, nil
+}
+
+func (f *sfnt) numGlyphs() int {
+	return int(binary.BigEndian.Uint16(f.tables["maxp"][4:]))
+}
+
+func (f *sfnt) glyphOffsets() []uint32 {
+	n := f.numGlyphs()
+	loca := f.tables["loca"]
+	offsets := make([]uint32, n+1)
+	longFormat := binary.BigEndian.Uint16(f.tables["head"][50:]) == 1
+	for i := range offsets {
+		if longFormat {
+			offsets[i] = binary.BigEndian.Uint32(loca[4*i:])
+		} else {
+			offsets[i] = uint32(binary.BigEndian.Uint16(loca[2*i:])) * 2
+		}
+	}
+	return offsets
+}
+
+// runeMap returns the Unicode to glyph mapping of the best available cmap
+// subtable, preferring the full-repertoire format 12 over BMP-only format 4.
+func (f *sfnt) runeMap() (map[rune]uint16, error) {
+	cmap := f.tables["cmap"]
+	numTables := int(binary.BigEndian.Uint16(cmap[2:]))
+
+	var format4, format12 []byte
+	for i := 0; i < numTables; i++ {
+		rec := cmap[4+8*i:]
+		platform := binary.BigEndian.Uint16(rec)
+		encoding := binary.BigEndian.Uint16(rec[2:])
+		sub := cmap[binary.BigEndian.Uint32(rec[4:]):]
+		unicode := platform == 0 || (platform == 3 && (encoding == 1 || encoding == 10))
+		if !unicode {
+			continue
+		}
+		switch binary.BigEndian.Uint16(sub) {
+		case 4:
+			format4 = sub
+		case 12:
+			format12 = sub
+		}
+	}
+
+	switch {
+	case format12 != nil:
+		return parseCmap12(format12), nil
+	case format4 != nil:
+		return parseCmap4(format4), nil
+	}
+	return nil, errors.New("no unicode cmap subtable")
+}
+
+func parseCmap4(sub []byte) map[rune]uint16 {
+	segCount := int(binary.BigEndian.Uint16(sub[6:])) / 2
+	ends := 14
+	starts := ends + 2*segCount + 2
+	deltas := starts + 2*segCount
+	rangeOffsets := deltas + 2*segCount
+
+	m := make(map[rune]uint16)
+	for i := 0; i < segCount; i++ {
+		end := binary.BigEndian.Uint16(sub[ends+2*i:])
+		start := binary.BigEndian.Uint16(sub[starts+2*i:])
+		delta := binary.BigEndian.Uint16(sub[deltas+2*i:])
+		rangeOffset := int(binary.BigEndian.Uint16(sub[rangeOffsets+2*i:]))
+
+		for c := uint32(start); c <= uint32(end) && c != 0xFFFF; c++ {
+			var gid uint16
+			if rangeOffset == 0 {
+				gid = uint16(c) + delta
+			} else {
+				addr := rangeOffsets + 2*i + rangeOffset + 2*int(c-uint32(start))
+				if addr+2 > len(sub) {
+					continue
+				}
+				if gid = binary.BigEndian.Uint16(sub[addr:]); gid != 0 {
+					gid += delta
+				}
+			}
+			if gid != 0 {
+				m[rune(c)] = gid
+			}
+		}
+	}
+	return m
+}
+
+func parseCmap12(sub []byte) map[rune]uint16 {
+	numGroups := int(binary.BigEndian.Uint32(sub[12:]))
+	m := make(map[rune]uint16)
+	for i := 0; i < numGroups; i++ {
+		group := sub[16+12*i:]
+		start := binary.BigEndian.Uint32(group)
+		end := binary.BigEndian.Uint32(group[4:])
+		gid := binary.BigEndian.Uint32(group[8:])
+		for c := start; c <= end; c++ {
+			m[rune(c)] = uint16(gid + c - start)
+		}
+	}
+	return m
+}
+
+// subset keeps the outlines of the glyphs needed to render runes and blanks
+// every other glyph. Glyph IDs are preserved so hmtx, GPOS and the other
+// glyph-indexed tables stay valid without being rewritten.
+func (f *sfnt) subset(runes []rune) (*sfnt, error) {
+	runeMap, err := f.runeMap()
+	if err != nil {
+		return nil, err
+	}
+
+	offsets := f.glyphOffsets()
+	glyf := f.tables["glyf"]
+	keep := map[uint16]bool{0: true}
+	cmap := make(map[rune]uint16)
+	for _, r := range runes {
+		if gid, ok := runeMap[r]; ok {
+			cmap[r] = gid
+			f.addGlyph(gid, keep, glyf, offsets)
+		}
+	}
+
+	out := &sfnt{tables: make(map[string][]byte, len(f.tables))}
+	for tag, data := range f.tables {
+		if !droppedTables[tag] {
+			out.tables[tag] = data
+		}
+	}
+
+	newGlyf := make([]byte, 0, len(glyf))
+	newLoca := make([]byte, 4*len(offsets))
+	for gid := 0; gid < len(offsets)-1; gid++ {
+		binary.BigEndian.PutUint32(newLoca[4*gid:], uint32(len(newGlyf)))
+		if keep[uint16(gid)] {
+			newGlyf = append(newGlyf, glyf[offsets[gid]:offsets[gid+1]]...)
+			for len(newGlyf)%4 != 0 {
+				newGlyf = append(newGlyf, 0)
+			}
+		}
+	}
+	binary.BigEndian.PutUint32(newLoca[4*(len(offsets)-1):], uint32(len(newGlyf)))
+
+	head := append([]byte(nil), f.tables["head"]...)
+	binary.BigEndian.PutUint16(head[50:], 1)
+
+	// Glyph names are never looked up at runtime, so post is reduced to the
+	// name-less version 3 header.
+	if post, ok := f.tables["post"]; ok && len(post) >= 32 {
+		post = append([]byte(nil), post[:32]...)
+		binary.BigEndian.PutUint32(post, 0x00030000)
+		out.tables["post"] = post
+	}
+
+	out.tables["glyf"] = newGlyf
+	out.tables["loca"] = newLoca
+	out.tables["head"] = head
+	out.tables["cmap"] = buildCmap(cmap)
+	return out, nil
+}
+
+func (f *sfnt) addGlyph(gid uint16, keep map[uint16]bool, glyf []byte, offsets []uint32) {
+	if int(gid) >= len(offsets)-1 {
+		return
+	}
+	keep[gid] = true
+
+	data := glyf[offsets[gid]:offsets[gid+1]]
+	if len(data) < 10 || int16(binary.BigEndian.Uint16(data)) >= 0 {
+		return
+	}
+
+	// Composite glyph: keep every component it references.
+	const (
+		argsAreWords   = 0x0001
+		haveScale      = 0x0008
+		moreComponents = 0x0020
+		haveXYScale    = 0x0040
+		haveTwoByTwo   = 0x0080
+	)
+	for p := 10; p+4 <= len(data); {
+		flags := binary.BigEndian.Uint16(data[p:])
+		component := binary.BigEndian.Uint16(data[p+2:])
+		if !keep[component] {
+			f.addGlyph(component, keep, glyf, offsets)
+		}
+
+		p += 4
+		if flags&argsAreWords != 0 {
+			p += 4
+		} else {
+			p += 2
+		}
+		switch {
+		case flags&haveScale != 0:
+			p += 2
+		case flags&haveXYScale != 0:
+			p += 4
+		case flags&haveTwoByTwo != 0:
+			p += 8
+		}
+		if flags&moreComponents == 0 {
+			break
+		}
+	}
+}
+
+func buildCmap(m map[rune]uint16) []byte {
+	var bmp, all []rune
+	for r := range m {
+		all = append(all, r)
+		if r < 0xFFFF {
+			bmp = append(bmp, r)
+		}
+	}
+	sort.Slice(bmp, func(i, j int) bool { return bmp[i] < bmp[j] })
+	sort.Slice(all, func(i, j int) bool { return all[i] < all[j] })
+
+	subtables := [][]byte{buildCmap4(bmp, m)}
+	if len(all) > len(bmp) {
+		subtables = append(subtables, buildCmap12(all, m))
+	}
+
+	header := make([]byte, 4+8*len(subtables))
+	binary.BigEndian.PutUint16(header[2:], uint16(len(subtables)))
+	offset := len(header)
+	out := header
+	for i, sub := range subtables {
+		encoding := uint16(1)
+		if i == 1 {
+			encoding = 10
+		}
+		binary.BigEndian.PutUint16(header[4+8*i:], 3)
+		binary.BigEndian.PutUint16(header[6+8*i:], encoding)
+		binary.BigEndian.PutUint32(header[8+8*i:], uint32(offset))
+		offset += len(sub)
+	}
+	for _, sub := range subtables {
+		out = append(out, sub...)
+	}
+	return out
+}
+
+func buildCmap4(runes []rune, m map[rune]uint16) []byte {
+	type segment struct{ start, end, delta uint16 }
+	var segments []segment
+	for _, r := range runes {
+		c, gid := uint16(r), m[r]
+		if n := len(segments); n > 0 {
+			last := &segments[n-1]
+			if c == last.end+1 && gid-c == last.delta {
+				last.end = c
+				continue
+			}
+		}
+		segments = append(segments, segment{start: c, end: c, delta: gid - c})
+	}
+	segments = append(segments, segment{start: 0xFFFF, end: 0xFFFF, delta: 1})
+
+	segCount := len(segments)
+	searchRange := 2 * (1 << (bits.Len(uint(segCount)) - 1))
+	length := 16 + 8*segCount
+
+	b := make([]byte, length)
+	binary.BigEndian.PutUint16(b, 4)
+	binary.BigEndian.PutUint16(b[2:], uint16(length))
+	binary.BigEndian.PutUint16(b[6:], uint16(2*segCount))
+	binary.BigEndian.PutUint16(b[8:], uint16(searchRange))
+	binary.BigEndian.PutUint16(b[10:], uint16(bits.Len(uint(searchRange/2))-1))
+	binary.BigEndian.PutUint16(b[12:], uint16(2*segCount-searchRange))
+
+	ends := 14
+	starts := ends + 2*segCount + 2
+	deltas := starts + 2*segCount
+	for i, seg := range segments {
+		binary.BigEndian.PutUint16(b[ends+2*i:], seg.end)
+		binary.BigEndian.PutUint16(b[starts+2*i:], seg.start)
+		binary.BigEndian.PutUint16(b[deltas+2*i:], seg.delta)
+	}
+	return b
+}
+
+func buildCmap12(runes []rune, m map[rune]uint16) []byte {
+	type group struct{ start, end, gid uint32 }
+	var groups []group
+	for _, r := range runes {
+		c, gid := uint32(r), uint32(m[r])
+		if n := len(groups); n > 0 {
+			last := &groups[n-1]
+			if c == last.end+1 && gid == last.gid+c-last.start {
+				last.end = c
+				continue
+			}
+		}
+		groups = append(groups, group{start: c, end: c, gid: gid})
+	}
+
+	length := 16 + 12*len(groups)
+	b := make([]byte, length)
+	binary.BigEndian.PutUint16(b, 12)
+	binary.BigEndian.PutUint32(b[4:], uint32(length))
+	binary.BigEndian.PutUint32(b[12:], uint32(len(groups)))
+	for i, g := range groups {
+		binary.BigEndian.PutUint32(b[16+12*i:], g.start)
+		binary.BigEndian.PutUint32(b[20+12*i:], g.end)
+		binary.BigEndian.PutUint32(b[24+12*i:], g.gid)
+	}
+	return b
+}
+
+func (f *sfnt) encode() []byte {
+	tags := make([]string, 0, len(f.tables))
+	for tag := range f.tables {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+
+	numTables := len(tags)
+	entrySelector := bits.Len(uint(numTables)) - 1
+	searchRange := 16 * (1 << entrySelector)
+
+	header := make([]byte, 12+16*numTables)
+	binary.BigEndian.PutUint32(header, 0x00010000)
+	binary.BigEndian.PutUint16(header[4:], uint16(numTables))
+	binary.BigEndian.PutUint16(header[6:], uint16(searchRange))
+	binary.BigEndian.PutUint16(header[8:], uint16(entrySelector))
+	binary.BigEndian.PutUint16(header[10:], uint16(16*numTables-searchRange))
+
+	var body []byte
+	headOffset := -1
+	for i, tag := range tags {
+		data := f.tables[tag]
+		if tag == "head" {
+			data = append([]byte(nil), data...)
+			binary.BigEndian.PutUint32(data[8:], 0)
+			headOffset = len(header) + len(body)
+		}
+
+		rec := header[12+16*i:]
+		copy(rec, tag)
+		binary.BigEndian.PutUint32(rec[4:], checksum(data))
+		binary.BigEndian.PutUint32(rec[8:], uint32(len(header)+len(body)))
+		binary.BigEndian.PutUint32(rec[12:], uint32(len(data)))
+
+		body = append(body, data...)
+		for len(body)%4 != 0 {
+			body = append(body, 0)
+		}
+	}
+
+	out := append(header, body...)
+	if headOffset >= 0 {
+		binary.BigEndian.PutUint32(out[headOffset+8:], 0xB1B0AFBA-checksum(out))
+	}
+	return out
+}
+
+func checksum(data []byte) uint32 {
+	var sum uint32
+	for i := 0; i < len(data); i += 4 {
+		var word [4]byte
+		copy(word[:], data[i:])
+		sum += binary.BigEndian.Uint32(word[:])
+	}
+	return sum
+}
@@ -0,0 +1,163 @@
+// Command gym serves the game as a reinforcement learning environment so an
+// agent written in any language can drive it.
+//
+// By default it speaks JSON lines over stdin and stdout: each request is one
+// of
+//
+//	{"cmd": "reset", "seed": 1}
+//	{"cmd": "step", "action": {"tilt": -0.5}}
+//
+// and each is answered with one line holding the observation, reward and
+// done flag, or {"error": "..."}. With -http it serves the same requests as
+// POST /reset and POST /step, keeping a separate environment per ?env= so
+// several agents can train against one server.
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"sync"
+
+	"github.com/ponyo877/suika-shaker/internal/gym"
+	"github.com/ponyo877/suika-shaker/internal/mode"
+	"github.com/ponyo877/suika-shaker/internal/sim"
+)
+
+var (
+	addr       = flag.String("http", "", "serve HTTP on this address (e.g. localhost:8000) instead of stdin/stdout")
+	modeName   = flag.String("mode", mode.Classic, "game mode to play")
+	frameSkip  = flag.Int("frame-skip", 4, "ticks each action is held for unless it sets repeat")
+	maxSeconds = flag.Int("max-seconds", 0, "truncate rounds after this many simulated seconds; 0 never truncates")
+	grid       = flag.String("grid", "", "add a downscaled board of WxH cells to observations, e.g. 24x40")
+)
+
+type request struct {
+	Cmd    string     `json:"cmd"`
+	Seed   int64      `json:"seed"`
+	Action gym.Action `json:"action"`
+}
+
+type response struct {
+	*gym.Result
+	Error string `json:"error,omitempty"`
+}
+
+func handle(env *gym.Env, req request) (*gym.Result, error) {
+	switch req.Cmd {
+	case "reset":
+		return &gym.Result{Observation: env.Reset(req.Seed)}, nil
+	case "step":
+		result, err := env.Step(req.Action)
+		return &result, err
+	}
+	return nil, fmt.Errorf("unknown cmd %q", req.Cmd)
+}
+
+func serveStdio(cfg gym.Config) error {
+	env := gym.New(cfg)
+	in := bufio.NewScanner(os.Stdin)
+	in.Buffer(make([]byte, 64*1024), 1<<20)
+	out := json.NewEncoder(os.Stdout)
+
+	for in.Scan() {
+		var req request
+		var resp response
+		if err := json.Unmarshal(in.Bytes(), &req); err != nil {
+			resp.Error = err.Error()
+		} else if result, err := handle(env, req); err != nil {
+			resp.Error = err.Error()
+		} else {
+			resp.Result = result
+		}
+		if err := out.Encode(resp); err != nil {
+			return err
+		}
+	}
+	return in.Err()
+}
+
+// server keeps one environment per client-chosen id.
+type server struct {
+	cfg  gym.Config
+	mu   sync.Mutex
+	envs map[string]*lockedEnv
+}
+
+type lockedEnv struct {
+	sync.Mutex
+	*gym.Env
+}
+
+func (s *server) env(id string) *lockedEnv {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	env := s.envs[id]
+	if env == nil {
+		env = &lockedEnv{Env: gym.New(s.cfg)}
+		s.envs[id] = env
+	}
+	return env
+}
+
+func (s *server) handler(cmd string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		req := request{Cmd: cmd}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		req.Cmd = cmd
+
+		env := s.env(r.URL.Query().Get("env"))
+		env.Lock()
+		result, err := handle(env.Env, req)
+		env.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		if err != nil {
+			w.WriteHeader(http.StatusConflict)
+			json.NewEncoder(w).Encode(response{Error: err.Error()})
+			return
+		}
+		json.NewEncoder(w).Encode(response{Result: result})
+	}
+}
+
+func main() {
+	flag.Parse()
+
+	m, ok := mode.Lookup(*modeName)
+	if !ok {
+		log.Fatalf("unknown mode %q", *modeName)
+	}
+	cfg := gym.Config{
+		Mode:      m,
+		FrameSkip: *frameSkip,
+		MaxTicks:  *maxSeconds * sim.TicksPerSecond,
+	}
+	if *grid != "" {
+		if _, err := fmt.Sscanf(*grid, "%dx%d", &cfg.GridWidth, &cfg.GridHeight); err != nil || cfg.GridWidth <= 0 || cfg.GridHeight <= 0 {
+			log.Fatalf("invalid -grid %q, want WxH", *grid)
+		}
+	}
+
+	if *addr == "" {
+		if err := serveStdio(cfg); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	s := &server{cfg: cfg, envs: map[string]*lockedEnv{}}
+	http.HandleFunc("POST /reset", s.handler("reset"))
+	http.HandleFunc("POST /step", s.handler("step"))
+	log.Printf("Serving the environment on http://%s", *addr)
+	log.Fatal(http.ListenAndServe(*addr, nil))
+}
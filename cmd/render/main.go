@@ -0,0 +1,202 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"image"
+	"image/color/palette"
+	"image/draw"
+	"image/gif"
+	"image/png"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/ponyo877/suika-shaker/internal/replay"
+	"github.com/ponyo877/suika-shaker/internal/sim"
+	"github.com/ponyo877/suika-shaker/internal/skin"
+	"github.com/ponyo877/suika-shaker/internal/ui"
+)
+
+var (
+	replayPath = flag.String("replay", "", "replay file recorded by the game (.replay.json)")
+	outPath    = flag.String("out", "render", "output .gif file, or a directory for a PNG sequence")
+	width      = flag.Int("width", ui.ScreenWidth, "output width in pixels")
+	height     = flag.Int("height", ui.ScreenHeight, "output height in pixels")
+	fps        = flag.Int("fps", 30, "output frame rate (must divide 60)")
+	themeName  = flag.String("theme", "light", "theme used to draw the board")
+	skinPath   = flag.String("skin", "", "skin directory or .zip to draw with")
+	holdFrames = flag.Int("hold", 0, "extra frames of the final board (default: one second)")
+)
+
+// renderer drives the simulation from a replay inside an ebiten loop so
+// frames are drawn by the same GPU path as the game, then reads them back.
+type renderer struct {
+	sim    *sim.Simulation
+	player *replay.Player
+	ui     *ui.Renderer
+	board  *ebiten.Image
+	frame  *ebiten.Image
+	pixels []byte
+	step   int
+	hold   int
+	count  int
+	anim   *gif.GIF
+	done   bool
+}
+
+func (r *renderer) Update() error {
+	if r.done {
+		return r.finish()
+	}
+
+	for i := 0; i < r.step; i++ {
+		in, ok := r.player.Next()
+		if !ok {
+			r.hold--
+			break
+		}
+		r.sim.Step(in)
+	}
+	if r.hold < 0 {
+		r.done = true
+		return nil
+	}
+
+	return r.render()
+}
+
+// render draws the current tick offscreen during Update, so every step is
+// captured exactly once regardless of how often ebiten calls Draw.
+func (r *renderer) render() error {
+	r.board.Clear()
+	r.ui.DrawBoard(r.board, r.sim)
+	if r.sim.State.ShowGameOverDialog {
+		r.ui.DrawGameOverDialog(r.board, r.sim.State.FinalScore, r.sim.State.FinalWatermelonHits, false)
+	}
+
+	r.frame.Clear()
+	op := &ebiten.DrawImageOptions{}
+	op.Filter = ebiten.FilterLinear
+	op.GeoM.Scale(float64(*width)/ui.ScreenWidth, float64(*height)/ui.ScreenHeight)
+	r.frame.DrawImage(r.board, op)
+	r.frame.ReadPixels(r.pixels)
+
+	return r.writeFrame()
+}
+
+func (r *renderer) Draw(screen *ebiten.Image) {
+	screen.DrawImage(r.board, nil)
+}
+
+func (r *renderer) writeFrame() error {
+	img := &image.RGBA{
+		Pix:    r.pixels,
+		Stride: 4 * *width,
+		Rect:   image.Rect(0, 0, *width, *height),
+	}
+	r.count++
+
+	if r.anim != nil {
+		frame := image.NewPaletted(img.Rect, palette.Plan9)
+		draw.FloydSteinberg.Draw(frame, img.Rect, img, image.Point{})
+		r.anim.Image = append(r.anim.Image, frame)
+		r.anim.Delay = append(r.anim.Delay, 100 / *fps)
+		return nil
+	}
+
+	f, err := os.Create(filepath.Join(*outPath, fmt.Sprintf("frame-%05d.png", r.count)))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return png.Encode(f, img)
+}
+
+func (r *renderer) finish() error {
+	if r.anim != nil {
+		f, err := os.Create(*outPath)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		if err := gif.EncodeAll(f, r.anim); err != nil {
+			return err
+		}
+	}
+	log.Printf("Rendered %d frames to %s", r.count, *outPath)
+	return ebiten.Termination
+}
+
+func (r *renderer) Layout(outsideWidth, outsideHeight int) (int, int) {
+	return ui.ScreenWidth, ui.ScreenHeight
+}
+
+func main() {
+	flag.Parse()
+
+	if *replayPath == "" {
+		log.Fatal("-replay is required")
+	}
+	if *fps <= 0 || sim.TicksPerSecond%*fps != 0 {
+		log.Fatalf("-fps must divide %d", sim.TicksPerSecond)
+	}
+	if *width <= 0 || *height <= 0 {
+		log.Fatal("-width and -height must be positive")
+	}
+
+	rp, err := replay.Load(*replayPath)
+	if err != nil {
+		log.Fatal("Failed to load replay:", err)
+	}
+
+	if *skinPath != "" {
+		s, err := skin.LoadPath(*skinPath)
+		if err != nil {
+			log.Fatal("Failed to load skin:", err)
+		}
+		if err := s.Apply(); err != nil {
+			log.Fatal("Failed to apply skin:", err)
+		}
+	}
+
+	theme, ok := ui.LookupTheme(*themeName)
+	if !ok {
+		log.Fatalf("unknown theme %q (available: %s)", *themeName, strings.Join(ui.ThemeNames(), ", "))
+	}
+
+	simulation := sim.New(rp.Mode, rp.Seed)
+	if rp.Level != nil {
+		simulation.Level = rp.Level
+		simulation.Reset(rp.Seed)
+	}
+
+	r := &renderer{
+		sim:    simulation,
+		player: rp.Player(),
+		ui:     ui.NewRenderer(),
+		board:  ebiten.NewImage(ui.ScreenWidth, ui.ScreenHeight),
+		frame:  ebiten.NewImage(*width, *height),
+		pixels: make([]byte, 4**width**height),
+		step:   sim.TicksPerSecond / *fps,
+		hold:   *holdFrames,
+	}
+	if r.hold == 0 {
+		r.hold = *fps
+	}
+	r.ui.SetTheme(theme)
+
+	if strings.EqualFold(filepath.Ext(*outPath), ".gif") {
+		r.anim = &gif.GIF{}
+	} else if err := os.MkdirAll(*outPath, 0755); err != nil {
+		log.Fatal("Failed to create output directory:", err)
+	}
+
+	ebiten.SetWindowTitle("suika-shaker render")
+	ebiten.SetWindowSize(ui.ScreenWidth/2, ui.ScreenHeight/2)
+	if err := ebiten.RunGame(r); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ponyo877/suika-shaker/internal/daily"
+	"github.com/ponyo877/suika-shaker/internal/sim"
+)
+
+const (
+	leaderboardFile = "daily-scores.json"
+	leaderboardSize = 100
+	maxNameLength   = 20
+	// maxDailyTicks is the longest daily round whose replay is played back.
+	// Classic rounds have no time limit, so without it a single submission
+	// could keep a core busy for as long as it liked.
+	maxDailyTicks = 60 * 60 * sim.TicksPerSecond
+)
+
+// leaderboard keeps every day's submissions in memory and mirrors them to a
+// JSON file so they survive restarts.
+type leaderboard struct {
+	mu      sync.Mutex
+	path    string
+	entries map[string][]daily.Entry
+}
+
+func newLeaderboard(path string) (*leaderboard, error) {
+	lb := &leaderboard{path: path, entries: map[string][]daily.Entry{}}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return lb, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &lb.entries); err != nil {
+		return nil, err
+	}
+	return lb, nil
+}
+
+func (lb *leaderboard) register(mux *http.ServeMux) {
+	mux.HandleFunc("/api/daily", lb.handleChallenge)
+	mux.HandleFunc(daily.ScoresPath, lb.handleScores)
+}
+
+func (lb *leaderboard) handleChallenge(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, daily.Today())
+}
+
+func (lb *leaderboard) handleScores(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		date := r.URL.Query().Get("date")
+		if date == "" {
+			date = daily.Today().Date
+		}
+		writeJSON(w, http.StatusOK, lb.top(date))
+	case http.MethodPost:
+		var e daily.Entry
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, replayLimit(maxDailyTicks))).Decode(&e); err != nil {
+			http.Error(w, "invalid entry", http.StatusBadRequest)
+			return
+		}
+		if status, err := lb.submit(e); err != nil {
+			http.Error(w, err.Error(), status)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (lb *leaderboard) submit(e daily.Entry) (int, error) {
+	challenge, ok := daily.Lookup(e.Date)
+	if !ok {
+		return http.StatusBadRequest, errors.New("invalid date")
+	}
+	// Rounds that straddle midnight UTC are still accepted for the day they
+	// started on.
+	now := time.Now()
+	if e.Date != daily.For(now).Date && e.Date != daily.For(now.Add(-time.Hour)).Date {
+		return http.StatusBadRequest, errors.New("challenge is closed")
+	}
+	if e.Seed != challenge.Seed {
+		return http.StatusBadRequest, errors.New("seed does not match the challenge")
+	}
+	if e.Name == "" || utf8.RuneCountInString(e.Name) > maxNameLength {
+		return http.StatusBadRequest, errors.New("invalid name")
+	}
+	// Only a replay of the day's challenge that reaches the score claimed
+	// gets on the board. Playing it back takes a while, so it is done before
+	// taking the lock.
+	_, _, score, err := playBack(e.Replay, challenge.Mode, challenge.Seed, maxDailyTicks)
+	switch {
+	case err != nil:
+		return http.StatusBadRequest, err
+	case score != e.Score:
+		return http.StatusBadRequest, fmt.Errorf("replay reaches %d, not the score %d", score, e.Score)
+	}
+	e.SubmittedAt = now.UTC()
+
+	lb.mu.Lock()
+	defer lb.mu.Unlock()
+
+	for _, existing := range lb.entries[e.Date] {
+		if existing.Name == e.Name {
+			return http.StatusConflict, errors.New("already submitted today")
+		}
+	}
+	lb.entries[e.Date] = append(lb.entries[e.Date], e)
+
+	if err := lb.save(); err != nil {
+		return http.StatusInternalServerError, err
+	}
+	return http.StatusCreated, nil
+}
+
+// top returns the best entries for date without their replays.
+func (lb *leaderboard) top(date string) []daily.Entry {
+	lb.mu.Lock()
+	entries := append([]daily.Entry(nil), lb.entries[date]...)
+	lb.mu.Unlock()
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].Score != entries[j].Score {
+			return entries[i].Score > entries[j].Score
+		}
+		return entries[i].SubmittedAt.Before(entries[j].SubmittedAt)
+	})
+	if len(entries) > leaderboardSize {
+		entries = entries[:leaderboardSize]
+	}
+	for i := range entries {
+		entries[i].Replay = nil
+	}
+	return entries
+}
+
+func (lb *leaderboard) save() error {
+	data, err := json.Marshal(lb.entries)
+	if err != nil {
+		return err
+	}
+	tmp := lb.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, lb.path)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"net/http"
+	"sort"
+	"sync"
+
+	"github.com/coder/websocket"
+	"github.com/coder/websocket/wsjson"
+	"github.com/ponyo877/suika-shaker/internal/online"
+)
+
+// liveHub relays each published board to everyone watching it. Frames are
+// never stored beyond the latest one, which new watchers receive straight
+// away so they do not start on an empty board.
+type liveHub struct {
+	mu      sync.Mutex
+	streams map[string]*liveStream
+}
+
+type liveStream struct {
+	publishing bool
+	latest     *online.Message
+	watchers   map[chan online.Message]struct{}
+}
+
+func newLiveHub() *liveHub {
+	return &liveHub{streams: map[string]*liveStream{}}
+}
+
+func (h *liveHub) register(mux *http.ServeMux) {
+	mux.HandleFunc("GET "+online.LivePath, h.handleList)
+	mux.HandleFunc(online.PublishPath("{stream}"), h.handlePublish)
+	mux.HandleFunc(online.WatchPath("{stream}"), h.handleWatch)
+}
+
+func (h *liveHub) handleList(w http.ResponseWriter, r *http.Request) {
+	h.mu.Lock()
+	streams := []online.Stream{}
+	for name, s := range h.streams {
+		if s.publishing {
+			streams = append(streams, online.Stream{Name: name, Watchers: len(s.watchers)})
+		}
+	}
+	h.mu.Unlock()
+
+	sort.Slice(streams, func(i, j int) bool { return streams[i].Name < streams[j].Name })
+	writeJSON(w, http.StatusOK, streams)
+}
+
+func (h *liveHub) handlePublish(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("stream")
+	if !online.ValidName(name) {
+		http.Error(w, "invalid stream name", http.StatusBadRequest)
+		return
+	}
+
+	h.mu.Lock()
+	s := h.stream(name)
+	busy := s.publishing
+	s.publishing = true
+	h.mu.Unlock()
+	if busy {
+		http.Error(w, "stream is already being published", http.StatusConflict)
+		return
+	}
+	defer h.unpublish(name)
+
+	conn, err := websocket.Accept(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer conn.CloseNow()
+
+	for {
+		var m online.Message
+		if err := wsjson.Read(r.Context(), conn, &m); err != nil {
+			return
+		}
+		if m.Type != online.TypeFrame || m.Board == nil {
+			continue
+		}
+
+		h.mu.Lock()
+		s.latest = &m
+		for watcher := range s.watchers {
+			deliver(watcher, m)
+		}
+		h.mu.Unlock()
+	}
+}
+
+func (h *liveHub) unpublish(name string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	s := h.streams[name]
+	s.publishing = false
+	s.latest = nil
+	for watcher := range s.watchers {
+		deliver(watcher, online.Message{Type: online.TypeEnd})
+	}
+	h.prune(name)
+}
+
+func (h *liveHub) handleWatch(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("stream")
+	if !online.ValidName(name) {
+		http.Error(w, "invalid stream name", http.StatusBadRequest)
+		return
+	}
+
+	conn, err := websocket.Accept(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer conn.CloseNow()
+	// Watchers only listen; reading keeps control frames flowing and notices
+	// when they leave.
+	ctx := conn.CloseRead(r.Context())
+
+	frames := make(chan online.Message, sendBuffer)
+	h.mu.Lock()
+	s := h.stream(name)
+	s.watchers[frames] = struct{}{}
+	if s.latest != nil {
+		deliver(frames, *s.latest)
+	}
+	h.mu.Unlock()
+	defer h.unwatch(name, frames)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case m := <-frames:
+			if err := wsjson.Write(ctx, conn, m); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func (h *liveHub) unwatch(name string, frames chan online.Message) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.streams[name].watchers, frames)
+	h.prune(name)
+}
+
+func (h *liveHub) stream(name string) *liveStream {
+	s := h.streams[name]
+	if s == nil {
+		s = &liveStream{watchers: map[chan online.Message]struct{}{}}
+		h.streams[name] = s
+	}
+	return s
+}
+
+// prune forgets a stream once nobody is publishing or watching it.
+func (h *liveHub) prune(name string) {
+	if s := h.streams[name]; !s.publishing && len(s.watchers) == 0 {
+		delete(h.streams, name)
+	}
+}
+
+// deliver queues m without blocking the hub; a connection too slow to drain
+// its buffer misses messages rather than holding everyone else up.
+func deliver(ch chan online.Message, m online.Message) {
+	select {
+	case ch <- m:
+	default:
+	}
+}
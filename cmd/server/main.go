@@ -7,6 +7,7 @@ import (
 	"crypto/x509"
 	"crypto/x509/pkix"
 	"encoding/pem"
+	"flag"
 	"fmt"
 	"log"
 	"math/big"
@@ -14,6 +15,8 @@ import (
 	"net/http"
 	"os"
 	"time"
+
+	"github.com/ponyo877/suika-shaker/internal/online"
 )
 
 const (
@@ -22,7 +25,11 @@ const (
 	port     = ":8443"
 )
 
+var roomDuration = flag.Duration("room-duration", online.DefaultDuration, "length of an online multiplayer round")
+
 func main() {
+	flag.Parse()
+
 	// Generate self-signed certificate if not exists
 	if _, err := os.Stat(certFile); os.IsNotExist(err) {
 		log.Println("Generating self-signed certificate...")
@@ -53,6 +60,19 @@ func main() {
 	fmt.Println("3. Tap 'visit this website'")
 	fmt.Println("4. Tap 'Visit Website' again to confirm")
 
+	// Setup daily challenge leaderboard
+	lb, err := newLeaderboard(leaderboardFile)
+	if err != nil {
+		log.Fatal("Failed to load leaderboard:", err)
+	}
+	lb.register(http.DefaultServeMux)
+
+	// Setup online multiplayer rooms
+	newRoomHub(*roomDuration).register(http.DefaultServeMux)
+
+	// Setup spectator streams
+	newLiveHub().register(http.DefaultServeMux)
+
 	// Setup file server
 	http.Handle("/", http.FileServer(http.Dir(".")))
 
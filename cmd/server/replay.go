@@ -0,0 +1,60 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/ponyo877/suika-shaker/internal/mode"
+	"github.com/ponyo877/suika-shaker/internal/replay"
+)
+
+const (
+	// segmentBytes bounds the JSON of one replay segment, and so of one tick
+	// at worst, since phones tilt a little almost every tick; replayOverhead
+	// covers the rules and the rest of the message.
+	segmentBytes   = 64
+	replayOverhead = 16 << 10
+)
+
+// replayLimit is the most a message carrying a replay of up to maxTicks
+// ticks can take.
+func replayLimit(maxTicks int) int64 {
+	return int64(maxTicks)*segmentBytes + replayOverhead
+}
+
+// playBack reads a submitted replay and, once it is known to be a round of m
+// from seed no longer than maxTicks, plays its inputs through a fresh round.
+// It returns the replay, how many ticks it really covers and the score it
+// actually reaches.
+//
+// This relies on the server's build reaching the same floating-point results
+// as the browser's, which Go only promises where it does not fuse multiply
+// and add; make test-replay checks it for the host it runs on.
+func playBack(data []byte, m mode.Mode, seed int64, maxTicks int) (*replay.Replay, int, int, error) {
+	rp, err := replay.Read(bytes.NewReader(data))
+	if err != nil {
+		return nil, 0, 0, fmt.Errorf("invalid replay: %v", err)
+	}
+	if !sameRound(rp, m, seed) {
+		return nil, 0, 0, errors.New("replay is not of this round")
+	}
+	ticks, ok := rp.Length(maxTicks)
+	switch {
+	case !ok:
+		return nil, 0, 0, fmt.Errorf("replay is longer than %d ticks", maxTicks)
+	case ticks != rp.Ticks:
+		return nil, 0, 0, fmt.Errorf("replay claims %d ticks but holds %d", rp.Ticks, ticks)
+	}
+	s, err := rp.Simulate(maxTicks)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	return rp, ticks, s.State.Score, nil
+}
+
+// sameRound reports whether rp was played under m from seed.
+func sameRound(rp *replay.Replay, m mode.Mode, seed int64) bool {
+	return rp.Seed == seed && rp.Level == nil && reflect.DeepEqual(rp.Mode, m)
+}
@@ -0,0 +1,396 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/coder/websocket"
+	"github.com/coder/websocket/wsjson"
+	"github.com/ponyo877/suika-shaker/internal/mode"
+	"github.com/ponyo877/suika-shaker/internal/online"
+	"github.com/ponyo877/suika-shaker/internal/snapshot"
+)
+
+const (
+	// startDelay gives every client time to receive the seed before the
+	// round begins.
+	startDelay = 3 * time.Second
+	// resultGrace is how long after the end time results are still accepted.
+	resultGrace = 5 * time.Second
+	// tickSlack is how far, in ticks, a client's clock may run ahead of the
+	// server's before its progress is rejected.
+	tickSlack = 2 * 60
+
+	sendBuffer = 64
+)
+
+// roomHub runs every room. The server owns the clock: it picks each round's
+// seed, start and end time, and only ranks scores it could check against
+// them.
+type roomHub struct {
+	mu       sync.Mutex
+	duration time.Duration
+	rooms    map[string]*room
+}
+
+type room struct {
+	name    string
+	state   string
+	round   int
+	seed    int64
+	mode    mode.Mode
+	startAt time.Time
+	endAt   time.Time
+	nextID  int
+	players []*roomPlayer
+	timer   *time.Timer
+}
+
+type roomPlayer struct {
+	online.Player
+	send   chan online.Message
+	result bool
+}
+
+func newRoomHub(duration time.Duration) *roomHub {
+	return &roomHub{duration: duration, rooms: map[string]*room{}}
+}
+
+func (h *roomHub) register(mux *http.ServeMux) {
+	mux.HandleFunc(online.RoomPath("{room}"), h.handleRoom)
+}
+
+func (h *roomHub) handleRoom(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("room")
+	if !online.ValidName(name) {
+		http.Error(w, "invalid room name", http.StatusBadRequest)
+		return
+	}
+	playerName := strings.TrimSpace(r.URL.Query().Get("name"))
+	if playerName == "" || utf8.RuneCountInString(playerName) > maxNameLength {
+		http.Error(w, "invalid name", http.StatusBadRequest)
+		return
+	}
+
+	conn, err := websocket.Accept(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer conn.CloseNow()
+	// A result carries the whole round's replay, far more than the default
+	// limit of 32 KiB.
+	conn.SetReadLimit(replayLimit(online.Mode(h.duration).TimeLimit + tickSlack))
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	p, err := h.join(name, playerName)
+	if err != nil {
+		wsjson.Write(ctx, conn, online.Message{Type: online.TypeError, Error: err.Error()})
+		conn.Close(websocket.StatusPolicyViolation, err.Error())
+		return
+	}
+	defer h.leave(name, p)
+
+	go func() {
+		for m := range p.send {
+			if err := wsjson.Write(ctx, conn, m); err != nil {
+				cancel()
+				return
+			}
+		}
+	}()
+
+	for {
+		var m online.Message
+		if err := wsjson.Read(ctx, conn, &m); err != nil {
+			return
+		}
+		h.handle(name, p, m)
+	}
+}
+
+func (h *roomHub) join(name, playerName string) (*roomPlayer, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	rm := h.rooms[name]
+	if rm == nil {
+		rm = &room{name: name, state: online.Waiting}
+		h.rooms[name] = rm
+	}
+	if rm.state != online.Waiting {
+		return nil, errors.New("a round is in progress")
+	}
+	if len(rm.players) >= online.MaxPlayers {
+		return nil, errors.New("room is full")
+	}
+
+	rm.nextID++
+	p := &roomPlayer{
+		Player: online.Player{ID: rm.nextID, Name: playerName},
+		send:   make(chan online.Message, sendBuffer),
+	}
+	rm.players = append(rm.players, p)
+	p.deliver(online.Message{Type: online.TypeWelcome, Player: p.ID, Room: name})
+	rm.broadcastState()
+	return p, nil
+}
+
+func (h *roomHub) leave(name string, p *roomPlayer) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	rm := h.rooms[name]
+	for i, other := range rm.players {
+		if other == p {
+			rm.players = append(rm.players[:i], rm.players[i+1:]...)
+			break
+		}
+	}
+	close(p.send)
+
+	if len(rm.players) == 0 {
+		if rm.timer != nil {
+			rm.timer.Stop()
+		}
+		delete(h.rooms, name)
+		return
+	}
+	if rm.state == online.Playing && rm.allFinished() {
+		rm.finish()
+		return
+	}
+	rm.broadcastState()
+}
+
+func (h *roomHub) handle(name string, p *roomPlayer, m online.Message) {
+	var played result
+	if m.Type == online.TypeResult {
+		played = h.replayResult(name, m)
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	rm := h.rooms[name]
+	switch m.Type {
+	case online.TypeStart:
+		if err := h.start(rm); err != nil {
+			p.deliver(online.Message{Type: online.TypeError, Error: err.Error()})
+		}
+	case online.TypeProgress:
+		if rm.state != online.Playing || p.result || p.Rejected || m.Board == nil {
+			return
+		}
+		if err := rm.checkProgress(p, *m.Board, time.Now()); err != nil {
+			log.Printf("room %s: rejected progress from %s: %v", rm.name, p.Name, err)
+			p.Rejected = true
+			rm.broadcastState()
+			return
+		}
+		p.Score = m.Board.Score
+		rm.broadcast(online.Message{Type: online.TypeProgress, Player: p.ID, Board: m.Board}, p)
+	case online.TypeResult:
+		if rm.state != online.Playing || p.result {
+			return
+		}
+		p.result, p.Finished = true, true
+		if err := rm.checkResult(p, m, played, time.Now()); err != nil {
+			log.Printf("room %s: rejected result from %s: %v", rm.name, p.Name, err)
+			p.Rejected = true
+		} else {
+			p.Score = m.Score
+		}
+		if rm.allFinished() {
+			rm.finish()
+			return
+		}
+		rm.broadcastState()
+	}
+}
+
+// start begins a round for everyone in the room. The start time is sent as a
+// delay rather than a timestamp so that clients need not share our clock.
+func (h *roomHub) start(rm *room) error {
+	if rm.state != online.Waiting {
+		return errors.New("a round is already in progress")
+	}
+	if len(rm.players) < online.MinPlayers {
+		return fmt.Errorf("at least %d players are needed", online.MinPlayers)
+	}
+
+	now := time.Now()
+	rm.state = online.Playing
+	rm.round++
+	rm.seed = rand.Int63()
+	rm.mode = online.Mode(h.duration)
+	rm.startAt = now.Add(startDelay)
+	rm.endAt = rm.startAt.Add(h.duration)
+	for _, p := range rm.players {
+		p.Score, p.Finished, p.Rejected, p.result = 0, false, false, false
+	}
+
+	round := rm.round
+	rm.timer = time.AfterFunc(rm.endAt.Sub(now), func() { h.end(rm, round) })
+
+	rm.broadcast(online.Message{
+		Type:     online.TypeStart,
+		Seed:     rm.seed,
+		StartIn:  startDelay.Milliseconds(),
+		Duration: int(h.duration.Seconds()),
+	}, nil)
+	rm.broadcastState()
+	return nil
+}
+
+// end tells the clients time is up, then waits for stragglers' results.
+func (h *roomHub) end(rm *room, round int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if rm.round != round || rm.state != online.Playing {
+		return
+	}
+
+	rm.broadcast(online.Message{Type: online.TypeEnd}, nil)
+	rm.timer = time.AfterFunc(resultGrace, func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		if rm.round == round && rm.state == online.Playing {
+			rm.finish()
+		}
+	})
+}
+
+// finish ranks the round and returns the room to waiting. Players who never
+// sent a result keep the last score they reported.
+func (rm *room) finish() {
+	if rm.timer != nil {
+		rm.timer.Stop()
+	}
+	rm.state = online.Waiting
+
+	standings := rm.standings()
+	sort.SliceStable(standings, func(i, j int) bool {
+		if standings[i].Rejected != standings[j].Rejected {
+			return !standings[i].Rejected
+		}
+		return standings[i].Score > standings[j].Score
+	})
+	rm.broadcast(online.Message{Type: online.TypeResults, Players: standings}, nil)
+	rm.broadcastState()
+}
+
+func (rm *room) allFinished() bool {
+	for _, p := range rm.players {
+		if !p.result {
+			return false
+		}
+	}
+	return true
+}
+
+// elapsedTicks is how many ticks a client that started on time has played.
+func (rm *room) elapsedTicks(now time.Time) int {
+	return int(now.Sub(rm.startAt).Seconds() * 60)
+}
+
+func (rm *room) checkProgress(p *roomPlayer, board snapshot.Snapshot, now time.Time) error {
+	switch {
+	case board.Tick > rm.elapsedTicks(now)+tickSlack:
+		return fmt.Errorf("tick %d is ahead of the round", board.Tick)
+	case board.Score < p.Score:
+		return fmt.Errorf("score went down from %d to %d", p.Score, board.Score)
+	case board.Score > online.MaxScore(rm.mode, board.Tick):
+		return fmt.Errorf("score %d is unreachable in %d ticks", board.Score, board.Tick)
+	}
+	return nil
+}
+
+// result is a result's replay played back against the round it was sent
+// in: how many ticks it covers and the score its inputs actually reach, or
+// why it could not be played back.
+type result struct {
+	round int
+	ticks int
+	score int
+	err   error
+}
+
+// replayResult plays a result's replay back against the room's round.
+// Playing back takes a while, so the lock every room shares is only held
+// while reading the round.
+func (h *roomHub) replayResult(name string, m online.Message) result {
+	h.mu.Lock()
+	rm := h.rooms[name]
+	playing := rm.state == online.Playing
+	round, rules, seed := rm.round, rm.mode, rm.seed
+	h.mu.Unlock()
+
+	if !playing {
+		return result{round: round, err: errors.New("no round is in progress")}
+	}
+	_, ticks, score, err := playBack(m.Replay, rules, seed, rules.TimeLimit+tickSlack)
+	return result{round: round, ticks: ticks, score: score, err: err}
+}
+
+// checkResult accepts a result only if its replay is of this round and,
+// played back here, reaches the score claimed.
+func (rm *room) checkResult(p *roomPlayer, m online.Message, played result, now time.Time) error {
+	if now.After(rm.endAt.Add(resultGrace)) {
+		return errors.New("result arrived after the round closed")
+	}
+	switch {
+	case played.round != rm.round:
+		return errors.New("result is for an earlier round")
+	case played.err != nil:
+		return played.err
+	case played.ticks > rm.mode.TimeLimit || played.ticks > rm.elapsedTicks(now)+tickSlack:
+		return fmt.Errorf("replay of %d ticks is longer than the round", played.ticks)
+	case m.Score < p.Score:
+		return fmt.Errorf("result %d is below reported score %d", m.Score, p.Score)
+	case m.Score > p.Score:
+		return fmt.Errorf("result %d is above reported score %d", m.Score, p.Score)
+	case played.score != m.Score:
+		return fmt.Errorf("replay reaches %d, not the result %d", played.score, m.Score)
+	}
+	return nil
+}
+
+func (rm *room) standings() []online.Player {
+	players := make([]online.Player, len(rm.players))
+	for i, p := range rm.players {
+		players[i] = p.Player
+	}
+	return players
+}
+
+func (rm *room) broadcastState() {
+	rm.broadcast(online.Message{
+		Type:    online.TypeRoom,
+		Room:    rm.name,
+		State:   rm.state,
+		Players: rm.standings(),
+	}, nil)
+}
+
+// broadcast sends m to every player other than except, which may be nil.
+func (rm *room) broadcast(m online.Message, except *roomPlayer) {
+	for _, p := range rm.players {
+		if p != except {
+			p.deliver(m)
+		}
+	}
+}
+
+func (p *roomPlayer) deliver(m online.Message) {
+	deliver(p.send, m)
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+	assets "github.com/ponyo877/suika-shaker/assets/image"
+	"github.com/ponyo877/suika-shaker/internal/i18n"
+	"github.com/ponyo877/suika-shaker/internal/physics"
+	"github.com/ponyo877/suika-shaker/internal/ui"
+)
+
+// evolutionRing places the in-game merge chain just under the home and
+// speaker buttons.
+var evolutionRing = struct{ x, y, radius, alpha float64 }{
+	x: ui.ScreenWidth - 65, y: 120, radius: 38, alpha: 0.8,
+}
+
+// fruitKinds lists the merge chain from the catalogue, leaving out power-ups.
+func fruitKinds() []assets.Kind {
+	var kinds []assets.Kind
+	for kind := assets.Min; kind <= assets.Max; kind++ {
+		if assets.Exists(kind) {
+			kinds = append(kinds, kind)
+		}
+	}
+	return kinds
+}
+
+func (g *Game) openEncyclopedia() {
+	g.state.ShowTitleScreen = false
+	g.state.ShowEncyclopedia = true
+	hideShareButton()
+}
+
+func (g *Game) handleEncyclopediaInput() {
+	if clicked, x, y := g.inputHandler.CheckMouseClick(); clicked {
+		g.handleEncyclopediaClick(x, y)
+	}
+	for _, touch := range g.inputHandler.CheckTouchInput() {
+		g.handleEncyclopediaClick(touch.X, touch.Y)
+	}
+}
+
+func (g *Game) handleEncyclopediaClick(x, y int) {
+	if g.inputHandler.IsButtonClicked(x, y, ui.HomeButtonConfig) {
+		g.returnToTitle()
+	}
+}
+
+func (g *Game) drawEncyclopedia(screen *ebiten.Image) {
+	kinds := fruitKinds()
+	entries := make([]ui.FruitEntry, len(kinds))
+	discovered := 0
+	for i, kind := range kinds {
+		entries[i] = ui.FruitEntry{
+			Kind:       kind,
+			Name:       i18n.T("fruit_unknown"),
+			Points:     i18n.Tf("fruit_points", kind.Score()),
+			Discovered: g.achievements.Discovered(kind, nil),
+		}
+		if entries[i].Discovered {
+			entries[i].Name = i18n.T("fruit_name_" + kind.String())
+			discovered++
+		}
+	}
+	subtitle := i18n.Tf("encyclopedia_total", discovered, len(kinds))
+	g.renderer.DrawEncyclopedia(screen, i18n.T("encyclopedia"), subtitle, entries, physics.PaddingBottom)
+	g.renderer.DrawHomeButton(screen)
+}
+
+// drawEvolutionRing shows the merge chain during play, counting fruits made
+// in the current game as discovered.
+func (g *Game) drawEvolutionRing(screen *ebiten.Image) {
+	discovered := func(kind assets.Kind) bool {
+		return g.achievements.Discovered(kind, g.state)
+	}
+	r := evolutionRing
+	g.renderer.DrawEvolutionRing(screen, fruitKinds(), discovered, r.x, r.y, r.radius, r.alpha)
+}
@@ -0,0 +1,170 @@
+package achievement
+
+import (
+	"encoding/json"
+	"log"
+	"time"
+
+	assets "github.com/ponyo877/suika-shaker/assets/image"
+	"github.com/ponyo877/suika-shaker/internal/gamestate"
+	"github.com/ponyo877/suika-shaker/internal/mode"
+	"github.com/ponyo877/suika-shaker/internal/sim"
+	"github.com/ponyo877/suika-shaker/internal/storage"
+)
+
+const progressKey = "achievements.json"
+
+// Achievement is a goal the player earns once and keeps.
+type Achievement struct {
+	ID     string
+	Label  string // i18n key of the name
+	Detail string // i18n key of what it takes
+	// Scored limits it to modes that count scores, so that it cannot be
+	// farmed in Zen or practice.
+	Scored bool
+	met    func(p *Progress, s *gamestate.State, m mode.Mode) bool
+}
+
+var achievements = []Achievement{
+	{
+		ID:     "first_watermelon",
+		Label:  "achievement_first_watermelon",
+		Detail: "achievement_first_watermelon_detail",
+		met: func(_ *Progress, s *gamestate.State, _ mode.Mode) bool {
+			return s.Created[assets.Watermelon] > 0
+		},
+	},
+	{
+		ID:     "watermelon_hits_5",
+		Label:  "achievement_watermelon_hits",
+		Detail: "achievement_watermelon_hits_detail",
+		met: func(_ *Progress, s *gamestate.State, _ mode.Mode) bool {
+			return s.WatermelonHits >= 5
+		},
+	},
+	{
+		ID:     "combo_10",
+		Label:  "achievement_combo",
+		Detail: "achievement_combo_detail",
+		met: func(_ *Progress, s *gamestate.State, _ mode.Mode) bool {
+			return s.MaxCombo >= 10
+		},
+	},
+	scoreAchievement("score_1000", 1000),
+	scoreAchievement("score_5000", 5000),
+	scoreAchievement("score_10000", 10000),
+	{
+		ID:     "survive_5m",
+		Label:  "achievement_survive",
+		Detail: "achievement_survive_detail",
+		Scored: true,
+		met: func(_ *Progress, s *gamestate.State, m mode.Mode) bool {
+			return !m.NoGameOver && !s.GameOver && s.Count >= 5*60*sim.TicksPerSecond
+		},
+	},
+	{
+		ID:     "games_100",
+		Label:  "achievement_games",
+		Detail: "achievement_games_detail",
+		met: func(p *Progress, _ *gamestate.State, _ mode.Mode) bool {
+			return p.Games >= 100
+		},
+	},
+}
+
+func scoreAchievement(id string, score int) Achievement {
+	return Achievement{
+		ID:     id,
+		Label:  "achievement_" + id,
+		Detail: "achievement_" + id + "_detail",
+		Scored: true,
+		met: func(_ *Progress, s *gamestate.State, _ mode.Mode) bool {
+			return s.Score >= score
+		},
+	}
+}
+
+// All returns every achievement in the order they are listed.
+func All() []Achievement {
+	return append([]Achievement(nil), achievements...)
+}
+
+// Progress is what the player has earned across games: the achievements
+// unlocked and the running totals some of them depend on.
+type Progress struct {
+	Unlocked map[string]time.Time `json:"unlocked"`
+	Games    int                  `json:"games"`
+	Created  map[assets.Kind]int  `json:"created"` // fruits made by merging, over every game
+}
+
+func Load() *Progress {
+	p := &Progress{}
+	if data, ok := storage.Load(progressKey); ok {
+		if err := json.Unmarshal([]byte(data), p); err != nil {
+			log.Printf("failed to read achievements: %v", err)
+		}
+	}
+	if p.Unlocked == nil {
+		p.Unlocked = map[string]time.Time{}
+	}
+	if p.Created == nil {
+		p.Created = map[assets.Kind]int{}
+	}
+	return p
+}
+
+func (p *Progress) Has(id string) bool {
+	_, ok := p.Unlocked[id]
+	return ok
+}
+
+// Discovered reports whether the player has seen kind: made it by merging in
+// an earlier game or in s, the game in progress (nil if none). Grapes are
+// never merged into but start every game, so they always count.
+func (p *Progress) Discovered(kind assets.Kind, s *gamestate.State) bool {
+	if kind == assets.Min || p.Created[kind] > 0 {
+		return true
+	}
+	return s != nil && s.Created[kind] > 0
+}
+
+// Check unlocks whatever the game in progress has earned and returns the
+// newly unlocked achievements, saving if there are any.
+func (p *Progress) Check(s *gamestate.State, m mode.Mode) []Achievement {
+	var unlocked []Achievement
+	for _, a := range achievements {
+		if p.Has(a.ID) || (a.Scored && !m.Scored) || !a.met(p, s, m) {
+			continue
+		}
+		p.Unlocked[a.ID] = time.Now()
+		unlocked = append(unlocked, a)
+	}
+	if len(unlocked) > 0 {
+		p.save()
+	}
+	return unlocked
+}
+
+// FinishGame adds a finished game to the totals, then checks it like Check.
+func (p *Progress) FinishGame(s *gamestate.State, m mode.Mode) []Achievement {
+	p.Games++
+	for kind, n := range s.Created {
+		p.Created[kind] += n
+	}
+	unlocked := p.Check(s, m)
+	if len(unlocked) == 0 {
+		p.save()
+	}
+	return unlocked
+}
+
+func (p *Progress) save() {
+	data, err := json.Marshal(p)
+	if err != nil {
+		log.Printf("failed to encode achievements: %v", err)
+		return
+	}
+	if err := storage.Save(progressKey, string(data)); err != nil {
+		log.Printf("failed to save achievements: %v", err)
+	}
+}
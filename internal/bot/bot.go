@@ -0,0 +1,193 @@
+// Package bot plays rounds without a player by choosing, every tick, which
+// way to tilt the board. It exists to balance drop rates and fruit scores
+// from many simulated rounds rather than hours of manual play.
+package bot
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/jakecoffman/cp/v2"
+	assets "github.com/ponyo877/suika-shaker/assets/image"
+	"github.com/ponyo877/suika-shaker/internal/sim"
+	"github.com/ponyo877/suika-shaker/internal/ui"
+	"github.com/ponyo877/suika-shaker/internal/versus"
+)
+
+// Policy decides the input for the next tick of a round.
+type Policy interface {
+	Name() string
+	Choose(s *sim.Simulation) versus.Control
+}
+
+// Policies names the policies NewPolicy can build.
+var Policies = []string{"level", "sway", "greedy", "lookahead"}
+
+// NewPolicy builds a policy by name. The lookahead policy searches horizon
+// ticks ahead every replan ticks.
+func NewPolicy(name string, horizon, replan int) (Policy, error) {
+	switch name {
+	case "level":
+		return Level{}, nil
+	case "sway":
+		return Sway{Period: 2 * sim.TicksPerSecond}, nil
+	case "greedy":
+		return Greedy{}, nil
+	case "lookahead":
+		return NewLookahead(horizon, replan), nil
+	}
+	return nil, fmt.Errorf("unknown policy %q (available: %s)", name, strings.Join(Policies, ", "))
+}
+
+// Actions are the tilts a search policy chooses between: level, two angles
+// to either side, and a shake.
+var Actions = []versus.Control{
+	{Tilt: 0},
+	{Tilt: -0.5},
+	{Tilt: 0.5},
+	{Tilt: -1},
+	{Tilt: 1},
+	{Shake: true},
+}
+
+// Fruit is a fruit's kind and position, as a policy sees it.
+type Fruit struct {
+	Kind assets.Kind
+	X, Y float64
+}
+
+// Fruits lists the fruits on the board in the order the space holds them.
+func Fruits(s *sim.Simulation) []Fruit {
+	var fruits []Fruit
+	s.Physics.GetSpace().EachBody(func(body *cp.Body) {
+		if kind, ok := body.UserData.(assets.Kind); ok {
+			p := body.Position()
+			fruits = append(fruits, Fruit{Kind: kind, X: p.X, Y: p.Y})
+		}
+	})
+	return fruits
+}
+
+// Level never tilts; it is the baseline other policies are measured against.
+type Level struct{}
+
+func (Level) Name() string { return "level" }
+
+func (Level) Choose(*sim.Simulation) versus.Control { return versus.Control{} }
+
+// Sway is scripted input: it tilts fully left and right in turn, Period
+// ticks each way, like a player rocking the board without looking.
+type Sway struct {
+	Period int
+}
+
+func (Sway) Name() string { return "sway" }
+
+func (w Sway) Choose(s *sim.Simulation) versus.Control {
+	if (s.State.Count/max(1, w.Period))%2 == 0 {
+		return versus.Control{Tilt: -1}
+	}
+	return versus.Control{Tilt: 1}
+}
+
+// Greedy steers towards the most valuable merge on the board: it finds the
+// largest pair of same-kind fruits and tilts so that the one further from
+// the wall rolls into the one against it.
+type Greedy struct{}
+
+func (Greedy) Name() string { return "greedy" }
+
+func (Greedy) Choose(s *sim.Simulation) versus.Control {
+	fruits := Fruits(s)
+	left, right := s.Physics.Bounds()
+	center := (left + right) / 2
+
+	var a, b Fruit
+	found := false
+	bestDist := math.Inf(1)
+	for i := range fruits {
+		for j := i + 1; j < len(fruits); j++ {
+			if fruits[i].Kind != fruits[j].Kind {
+				continue
+			}
+			if found && fruits[i].Kind < a.Kind {
+				continue
+			}
+			d := math.Hypot(fruits[i].X-fruits[j].X, fruits[i].Y-fruits[j].Y)
+			if !found || fruits[i].Kind > a.Kind || d < bestDist {
+				a, b, bestDist, found = fruits[i], fruits[j], d, true
+			}
+		}
+	}
+	if !found {
+		return versus.Control{}
+	}
+
+	// Roll towards whichever of the pair sits closer to its wall, so the
+	// wall holds it while the other one arrives.
+	anchor := a
+	if math.Abs(b.X-center) > math.Abs(a.X-center) {
+		anchor = b
+	}
+	tilt := 1.0
+	if anchor.X < center {
+		tilt = -1
+	}
+	// Tilt gently when the pair is already close so it does not overshoot.
+	tilt *= math.Min(1, 0.3+bestDist/ui.ScreenWidth)
+	return versus.Control{Tilt: tilt}
+}
+
+// Lookahead tries every action on a clone of the board for Horizon ticks and
+// plays the one that leads to the best position, re-planning every Replan
+// ticks and holding its choice in between.
+type Lookahead struct {
+	Horizon int
+	Replan  int
+
+	choice versus.Control
+	held   int
+}
+
+func NewLookahead(horizon, replan int) *Lookahead {
+	return &Lookahead{Horizon: horizon, Replan: max(1, replan)}
+}
+
+func (l *Lookahead) Name() string { return "lookahead" }
+
+func (l *Lookahead) Choose(s *sim.Simulation) versus.Control {
+	if l.held > 0 {
+		l.held--
+		return l.choice
+	}
+
+	best := math.Inf(-1)
+	for _, action := range Actions {
+		trial := s.Clone()
+		in := action.Input()
+		for i := 0; i < l.Horizon && !trial.State.GameOver; i++ {
+			trial.Step(in)
+		}
+		if v := Evaluate(trial); v > best {
+			best, l.choice = v, action
+		}
+	}
+	l.held = l.Replan - 1
+	return l.choice
+}
+
+// Evaluate scores a board for search: points earned, less a penalty for how
+// crowded it is, with losing worse than anything else.
+func Evaluate(s *sim.Simulation) float64 {
+	if s.State.GameOver && s.Mode.Remaining(s.State.Count) != 0 {
+		return math.Inf(-1)
+	}
+
+	crowding := 0.0
+	for _, f := range Fruits(s) {
+		// Fruits high up the board are the ones that block new drops.
+		crowding += 1 + 2*(1-f.Y/ui.ScreenHeight)
+	}
+	return float64(s.State.Score) - 5*crowding
+}
@@ -0,0 +1,144 @@
+package bot
+
+import (
+	"math"
+	"sort"
+	"sync"
+
+	assets "github.com/ponyo877/suika-shaker/assets/image"
+
+	"github.com/ponyo877/suika-shaker/internal/mode"
+	"github.com/ponyo877/suika-shaker/internal/sim"
+)
+
+// Result is the outcome of one round played by a policy.
+type Result struct {
+	Seed           int64 `json:"seed"`
+	Score          int   `json:"score"`
+	Ticks          int   `json:"ticks"`
+	Drops          int   `json:"drops"`
+	WatermelonHits int   `json:"watermelon_hits"`
+	Watermelons    int   `json:"watermelons"` // watermelons made by merging
+	MaxCombo       int   `json:"max_combo"`
+	Survived       bool  `json:"survived"` // the round hit maxTicks or its time limit rather than overflowing
+}
+
+// Play runs a round of m from seed under p until it ends or maxTicks pass.
+func Play(m mode.Mode, seed int64, p Policy, maxTicks int) Result {
+	s := sim.New(m, seed)
+	s.State.ShowTitleScreen = false
+	for s.State.Count < maxTicks && !s.State.GameOver {
+		s.Step(p.Choose(s).Input())
+	}
+
+	return Result{
+		Seed:           seed,
+		Score:          s.State.Score,
+		Ticks:          s.State.Count,
+		Drops:          s.State.Drops,
+		WatermelonHits: s.State.WatermelonHits,
+		Watermelons:    s.State.Created[assets.Watermelon],
+		MaxCombo:       s.State.MaxCombo,
+		Survived:       !s.State.GameOver || s.Mode.Remaining(s.State.Count) == 0,
+	}
+}
+
+// PlayMany plays rounds from seeds first, first+1, ... on workers goroutines.
+// Each round gets a fresh policy from newPolicy, since policies may keep
+// state between ticks.
+func PlayMany(m mode.Mode, first int64, rounds int, newPolicy func() Policy, maxTicks, workers int) []Result {
+	results := make([]Result, rounds)
+	next := make(chan int, rounds)
+	for i := range results {
+		next <- i
+	}
+	close(next)
+
+	var wg sync.WaitGroup
+	for w := 0; w < max(1, workers); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				results[i] = Play(m, first+int64(i), newPolicy(), maxTicks)
+			}
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+// Summary describes the distribution of scores over many rounds.
+type Summary struct {
+	Rounds   int     `json:"rounds"`
+	Mean     float64 `json:"mean"`
+	StdDev   float64 `json:"stddev"`
+	Min      int     `json:"min"`
+	P10      int     `json:"p10"`
+	Median   int     `json:"median"`
+	P90      int     `json:"p90"`
+	Max      int     `json:"max"`
+	Survived float64 `json:"survived"` // fraction of rounds that did not overflow
+	MeanTime float64 `json:"mean_seconds"`
+
+	WatermelonRate float64 `json:"watermelon_rate"` // fraction of rounds that made a watermelon
+}
+
+func Summarize(results []Result) Summary {
+	if len(results) == 0 {
+		return Summary{}
+	}
+
+	scores := make([]int, len(results))
+	sum, ticks, survived, watermelon := 0.0, 0.0, 0, 0
+	for i, r := range results {
+		scores[i] = r.Score
+		sum += float64(r.Score)
+		ticks += float64(r.Ticks)
+		if r.Survived {
+			survived++
+		}
+		if r.Watermelons > 0 {
+			watermelon++
+		}
+	}
+	sort.Ints(scores)
+
+	n := float64(len(results))
+	mean := sum / n
+	variance := 0.0
+	for _, score := range scores {
+		variance += (float64(score) - mean) * (float64(score) - mean)
+	}
+
+	percentile := func(p float64) int {
+		return scores[int(math.Round(p*float64(len(scores)-1)))]
+	}
+	return Summary{
+		Rounds:   len(results),
+		Mean:     mean,
+		StdDev:   math.Sqrt(variance / n),
+		Min:      scores[0],
+		P10:      percentile(0.1),
+		Median:   percentile(0.5),
+		P90:      percentile(0.9),
+		Max:      scores[len(scores)-1],
+		Survived: float64(survived) / n,
+		MeanTime: ticks / n / sim.TicksPerSecond,
+
+		WatermelonRate: float64(watermelon) / n,
+	}
+}
+
+// Histogram counts scores into buckets of width, starting from zero.
+func Histogram(results []Result, width int) []int {
+	var buckets []int
+	for _, r := range results {
+		i := max(0, r.Score) / max(1, width)
+		for len(buckets) <= i {
+			buckets = append(buckets, 0)
+		}
+		buckets[i]++
+	}
+	return buckets
+}
@@ -0,0 +1,127 @@
+package clip
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/gif"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+const (
+	DefaultSeconds = 6
+	DefaultFPS     = 12
+	DefaultScale   = 0.3
+	ticksPerSecond = 60
+)
+
+// palette is a 6x6x6 colour cube so frames can be quantised with arithmetic
+// instead of a nearest-colour search, which is too slow to run every capture.
+var palette = func() color.Palette {
+	p := make(color.Palette, 0, 216)
+	for r := 0; r < 6; r++ {
+		for g := 0; g < 6; g++ {
+			for b := 0; b < 6; b++ {
+				p = append(p, color.RGBA{uint8(r * 51), uint8(g * 51), uint8(b * 51), 255})
+			}
+		}
+	}
+	return p
+}()
+
+// Recorder keeps the last few seconds of play as downscaled GIF frames in a
+// ring buffer.
+type Recorder struct {
+	width    int
+	height   int
+	interval int
+	scale    float64
+	small    *ebiten.Image
+	pixels   []byte
+	frames   []*image.Paletted
+	next     int
+	count    int
+	tick     int
+}
+
+// NewRecorder buffers seconds of a screenWidth x screenHeight screen at fps
+// frames a second, each shrunk by scale.
+func NewRecorder(screenWidth, screenHeight, seconds, fps int, scale float64) *Recorder {
+	w := int(float64(screenWidth) * scale)
+	h := int(float64(screenHeight) * scale)
+	return &Recorder{
+		width:    w,
+		height:   h,
+		interval: max(1, ticksPerSecond/fps),
+		scale:    scale,
+		small:    ebiten.NewImage(w, h),
+		pixels:   make([]byte, 4*w*h),
+		frames:   make([]*image.Paletted, seconds*fps),
+	}
+}
+
+// Capture stores a downscaled copy of screen every interval ticks, overwriting
+// the oldest frame once the buffer is full.
+func (r *Recorder) Capture(screen *ebiten.Image) {
+	r.tick++
+	if r.tick%r.interval != 0 {
+		return
+	}
+
+	r.small.Clear()
+	op := &ebiten.DrawImageOptions{}
+	op.Filter = ebiten.FilterLinear
+	op.GeoM.Scale(r.scale, r.scale)
+	r.small.DrawImage(screen, op)
+	r.small.ReadPixels(r.pixels)
+
+	frame := r.frames[r.next]
+	if frame == nil {
+		frame = image.NewPaletted(image.Rect(0, 0, r.width, r.height), palette)
+		r.frames[r.next] = frame
+	}
+	for i := 0; i < r.width*r.height; i++ {
+		px := r.pixels[4*i:]
+		frame.Pix[i] = uint8(36*quantize(px[0]) + 6*quantize(px[1]) + quantize(px[2]))
+	}
+
+	r.next = (r.next + 1) % len(r.frames)
+	r.count = min(r.count+1, len(r.frames))
+}
+
+func quantize(v byte) int {
+	return (int(v) + 25) / 51
+}
+
+// Len returns how many frames are buffered.
+func (r *Recorder) Len() int {
+	return r.count
+}
+
+// Reset drops every buffered frame, ready for a new round.
+func (r *Recorder) Reset() {
+	r.next = 0
+	r.count = 0
+	r.tick = 0
+}
+
+// EncodeGIF returns the buffered frames, oldest first, as a looping GIF.
+func (r *Recorder) EncodeGIF() ([]byte, error) {
+	delay := r.interval * 100 / ticksPerSecond
+	anim := &gif.GIF{}
+	start := (r.next - r.count + len(r.frames)) % len(r.frames)
+	for i := 0; i < r.count; i++ {
+		anim.Image = append(anim.Image, r.frames[(start+i)%len(r.frames)])
+		anim.Delay = append(anim.Delay, delay)
+	}
+	if r.count > 0 {
+		anim.Delay[r.count-1] = 200
+	}
+
+	var buf bytes.Buffer
+	if err := gif.EncodeAll(&buf, anim); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,136 @@
+package daily
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"net/http"
+	"time"
+
+	"github.com/ponyo877/suika-shaker/internal/mode"
+	"github.com/ponyo877/suika-shaker/internal/storage"
+)
+
+const (
+	DateLayout = "2006-01-02"
+	ScoresPath = "/api/daily/scores"
+	attemptKey = "daily-attempt.json"
+)
+
+// Modifier is the twist applied to the classic rules for a day.
+type Modifier struct {
+	Name  string `json:"name"`
+	Label string `json:"label"` // i18n key
+	apply func(m *mode.Mode)
+}
+
+var modifiers = []Modifier{
+	{Name: "narrow-arena", Label: "daily_narrow_arena", apply: func(m *mode.Mode) { m.ArenaWidth = 340 }},
+	{Name: "fast-drops", Label: "daily_fast_drops", apply: func(m *mode.Mode) { m.DropInterval = 30 }},
+	{Name: "head-start", Label: "daily_head_start", apply: func(m *mode.Mode) { m.StartingFruits = 8 }},
+}
+
+// Challenge is the round everyone plays on a given UTC date.
+type Challenge struct {
+	Date     string    `json:"date"`
+	Seed     int64     `json:"seed"`
+	Modifier Modifier  `json:"modifier"`
+	Mode     mode.Mode `json:"mode"`
+}
+
+// For derives the challenge for t's UTC date, so every client and the server
+// agree on it without talking to each other.
+func For(t time.Time) Challenge {
+	date := t.UTC().Format(DateLayout)
+
+	h := fnv.New64a()
+	h.Write([]byte("suika-shaker daily " + date))
+	sum := h.Sum64()
+
+	modifier := modifiers[(sum>>32)%uint64(len(modifiers))]
+	m, _ := mode.Lookup(mode.Classic)
+	m.Name = mode.Daily
+	m.Label = "mode_daily"
+	modifier.apply(&m)
+
+	return Challenge{
+		Date:     date,
+		Seed:     int64(sum &^ (1 << 63)),
+		Modifier: modifier,
+		Mode:     m,
+	}
+}
+
+func Today() Challenge {
+	return For(time.Now())
+}
+
+func Lookup(date string) (Challenge, bool) {
+	t, err := time.Parse(DateLayout, date)
+	if err != nil {
+		return Challenge{}, false
+	}
+	return For(t), true
+}
+
+// Attempt records the player's one try at a day's challenge.
+type Attempt struct {
+	Date     string `json:"date"`
+	Score    int    `json:"score"`
+	Finished bool   `json:"finished"`
+}
+
+func LastAttempt() (Attempt, bool) {
+	data, ok := storage.Load(attemptKey)
+	if !ok {
+		return Attempt{}, false
+	}
+	var a Attempt
+	if err := json.Unmarshal([]byte(data), &a); err != nil {
+		return Attempt{}, false
+	}
+	return a, true
+}
+
+// Played reports whether the challenge for date has already been started.
+func Played(date string) (Attempt, bool) {
+	a, ok := LastAttempt()
+	return a, ok && a.Date == date
+}
+
+func (a Attempt) Save() error {
+	data, err := json.Marshal(a)
+	if err != nil {
+		return err
+	}
+	return storage.Save(attemptKey, string(data))
+}
+
+// Entry is one leaderboard submission.
+type Entry struct {
+	Date           string          `json:"date"`
+	Name           string          `json:"name"`
+	Score          int             `json:"score"`
+	WatermelonHits int             `json:"watermelon_hits"`
+	Seed           int64           `json:"seed"`
+	Replay         json.RawMessage `json:"replay,omitempty"`
+	SubmittedAt    time.Time       `json:"submitted_at"`
+}
+
+// Submit posts e to the leaderboard served at baseURL.
+func Submit(baseURL string, e Entry) error {
+	body, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	resp, err := http.Post(baseURL+ScoresPath, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		return fmt.Errorf("leaderboard returned %s", resp.Status)
+	}
+	return nil
+}
@@ -1,18 +1,22 @@
 package gamestate
 
 import (
-	"math"
+	"time"
 
-	"github.com/hajimehoshi/ebiten/v2"
 	assets "github.com/ponyo877/suika-shaker/assets/image"
 )
 
+const ComboWindow = 60
+
 type State struct {
 	Count               int
 	DropCount           int
 	SpawnFailCount      int
+	OutOfBounds         int  // ticks a fruit has spent outside the board
+	Danger              bool // the board is close to ending the round
 	Score               int
-	HiScore             int
+	HiScores            map[string]int
+	Mode                string
 	WatermelonHits      int
 	NextFruit           NextFruit
 	GameOver            bool
@@ -20,9 +24,23 @@ type State struct {
 	Muted               bool
 	ShowGameOverDialog  bool
 	ShowTitleScreen     bool
-	GameOverScreenshot  *ebiten.Image
+	ShowLevelSelect     bool
+	ShowAchievements    bool
+	ShowEncyclopedia    bool
 	FinalScore          int
 	FinalWatermelonHits int
+	Combo               int
+	MaxCombo            int
+	LastMergeCount      int
+	FinalMaxCombo       int
+	FinishedAt          time.Time
+	Drops               int
+	Created             map[assets.Kind]int
+	LevelCleared        bool
+	Stars               int
+	Frozen              int         // ticks of zero gravity left from a freeze
+	PowerUp             assets.Kind // the last power-up set off, 0 if none
+	PowerUpAt           int         // the tick it went off
 }
 
 type NextFruit struct {
@@ -35,7 +53,8 @@ type NextFruit struct {
 func NewState() *State {
 	return &State{
 		Score:           0,
-		HiScore:         0,
+		HiScores:        map[string]int{},
+		Created:         map[assets.Kind]int{},
 		WatermelonHits:  0,
 		ShowTitleScreen: true,
 	}
@@ -61,6 +80,21 @@ func (s *State) IncrementWatermelonHits() {
 	s.WatermelonHits++
 }
 
+func (s *State) RegisterMerge() {
+	if s.Combo > 0 && s.Count-s.LastMergeCount <= ComboWindow {
+		s.Combo++
+	} else {
+		s.Combo = 1
+	}
+	s.LastMergeCount = s.Count
+	s.MaxCombo = max(s.MaxCombo, s.Combo)
+}
+
+// RegisterCreated counts a fruit produced by a merge.
+func (s *State) RegisterCreated(kind assets.Kind) {
+	s.Created[kind]++
+}
+
 func (s *State) TriggerGameOver() {
 	if !s.GameOver {
 		s.GameOver = true
@@ -74,19 +108,39 @@ func (s *State) PrepareGameOverDialog() {
 	s.ShowGameOverDialog = true
 	s.FinalScore = s.Score
 	s.FinalWatermelonHits = s.WatermelonHits
-	s.HiScore = int(math.Max(float64(s.Score), float64(s.HiScore)))
+	s.FinalMaxCombo = s.MaxCombo
+	s.FinishedAt = time.Now()
+	s.HiScores[s.Mode] = max(s.Score, s.HiScores[s.Mode])
+}
+
+// HiScore returns the best score for the mode being played.
+func (s *State) HiScore() int {
+	return s.HiScores[s.Mode]
 }
 
 func (s *State) Reset() {
+	s.Count = 0
+	s.DropCount = 0
 	s.Score = 0
 	s.WatermelonHits = 0
 	s.GameOver = false
 	s.GameOverSE = false
 	s.ShowGameOverDialog = false
-	s.GameOverScreenshot = nil
 	s.FinalScore = 0
 	s.FinalWatermelonHits = 0
 	s.SpawnFailCount = 0
+	s.OutOfBounds = 0
+	s.Danger = false
+	s.Combo = 0
+	s.MaxCombo = 0
+	s.FinalMaxCombo = 0
+	s.Drops = 0
+	s.Created = map[assets.Kind]int{}
+	s.LevelCleared = false
+	s.Stars = 0
+	s.Frozen = 0
+	s.PowerUp = 0
+	s.PowerUpAt = 0
 }
 
 func (s *State) SetMuted(muted bool) {
@@ -0,0 +1,179 @@
+// Package gym wraps the headless simulation as a reinforcement learning
+// environment in the style of OpenAI Gym: Reset starts a round from a seed
+// and Step applies an action and returns what the agent observes, its
+// reward and whether the round is over.
+package gym
+
+import (
+	"errors"
+	"math"
+
+	"github.com/jakecoffman/cp/v2"
+	assets "github.com/ponyo877/suika-shaker/assets/image"
+	"github.com/ponyo877/suika-shaker/internal/mode"
+	"github.com/ponyo877/suika-shaker/internal/physics"
+	"github.com/ponyo877/suika-shaker/internal/sim"
+	"github.com/ponyo877/suika-shaker/internal/ui"
+	"github.com/ponyo877/suika-shaker/internal/versus"
+)
+
+var ErrNotReset = errors.New("gym: Step called before Reset")
+
+// Action tilts or shakes the board, as a player would, or sets gravity
+// directly.
+type Action struct {
+	Tilt    float64 `json:"tilt"`              // -1 (left) to 1 (right)
+	Shake   bool    `json:"shake,omitempty"`   // flip gravity upwards
+	Gravity *[2]int `json:"gravity,omitempty"` // raw gravity, overriding tilt and shake
+	Repeat  int     `json:"repeat,omitempty"`  // ticks to hold the action; 0 uses Config.FrameSkip
+}
+
+func (a Action) input() sim.Input {
+	if a.Gravity != nil {
+		return sim.Input{GravityX: a.Gravity[0], GravityY: a.Gravity[1]}
+	}
+	return versus.Control{Tilt: a.Tilt, Shake: a.Shake}.Input()
+}
+
+type Fruit struct {
+	Kind  int     `json:"kind"`
+	X     float64 `json:"x"`
+	Y     float64 `json:"y"`
+	VX    float64 `json:"vx"`
+	VY    float64 `json:"vy"`
+	Angle float64 `json:"angle"`
+}
+
+type NextFruit struct {
+	Kind int     `json:"kind"`
+	X    float64 `json:"x"`
+	Y    float64 `json:"y"`
+}
+
+// Observation is the state of the board after a step. Fruits lists every
+// fruit; Grid, when enabled, is the board downscaled to rows of cells holding
+// the kind of fruit covering each cell's centre, or 0 where it is empty.
+type Observation struct {
+	Tick      int       `json:"tick"`
+	Score     int       `json:"score"`
+	Remaining int       `json:"remaining"` // ticks left in a timed mode, or -1
+	Next      NextFruit `json:"next"`
+	Fruits    []Fruit   `json:"fruits"`
+	Grid      [][]int   `json:"grid,omitempty"`
+}
+
+type Result struct {
+	Observation Observation `json:"observation"`
+	Reward      float64     `json:"reward"`    // score gained during the step
+	Done        bool        `json:"done"`      // the round ended by overflowing or its time limit
+	Truncated   bool        `json:"truncated"` // the round was cut off at Config.MaxTicks
+}
+
+type Config struct {
+	Mode       mode.Mode
+	FrameSkip  int // ticks each action is held for unless it says otherwise
+	MaxTicks   int // 0 lets rounds run until they end
+	GridWidth  int // 0 leaves Grid out of observations
+	GridHeight int
+}
+
+type Env struct {
+	cfg Config
+	sim *sim.Simulation
+}
+
+func New(cfg Config) *Env {
+	cfg.FrameSkip = max(1, cfg.FrameSkip)
+	return &Env{cfg: cfg}
+}
+
+func (e *Env) Reset(seed int64) Observation {
+	e.sim = sim.New(e.cfg.Mode, seed)
+	e.sim.State.ShowTitleScreen = false
+	return e.observe()
+}
+
+func (e *Env) Step(a Action) (Result, error) {
+	if e.sim == nil {
+		return Result{}, ErrNotReset
+	}
+
+	state := e.sim.State
+	before := state.Score
+	repeat := a.Repeat
+	if repeat <= 0 {
+		repeat = e.cfg.FrameSkip
+	}
+	in := a.input()
+	for i := 0; i < repeat && !e.done() && !e.truncated(); i++ {
+		e.sim.Step(in)
+	}
+
+	return Result{
+		Observation: e.observe(),
+		Reward:      float64(state.Score - before),
+		Done:        e.done(),
+		Truncated:   !e.done() && e.truncated(),
+	}, nil
+}
+
+func (e *Env) done() bool {
+	return e.sim.State.GameOver
+}
+
+func (e *Env) truncated() bool {
+	return e.cfg.MaxTicks > 0 && e.sim.State.Count >= e.cfg.MaxTicks
+}
+
+func (e *Env) observe() Observation {
+	state := e.sim.State
+	obs := Observation{
+		Tick:      state.Count,
+		Score:     state.Score,
+		Remaining: e.sim.Remaining(),
+		Next:      NextFruit{Kind: int(state.NextFruit.Kind), X: state.NextFruit.X, Y: state.NextFruit.Y},
+		Fruits:    []Fruit{},
+	}
+
+	space := e.sim.Physics.GetSpace()
+	space.EachBody(func(body *cp.Body) {
+		kind, ok := body.UserData.(assets.Kind)
+		if !ok {
+			return
+		}
+		p, v := body.Position(), body.Velocity()
+		obs.Fruits = append(obs.Fruits, Fruit{
+			Kind:  int(kind),
+			X:     p.X,
+			Y:     p.Y - physics.PaddingBottom,
+			VX:    v.X,
+			VY:    v.Y,
+			Angle: math.Mod(body.Angle(), 2*math.Pi),
+		})
+	})
+
+	if e.cfg.GridWidth > 0 && e.cfg.GridHeight > 0 {
+		obs.Grid = e.grid()
+	}
+	return obs
+}
+
+func (e *Env) grid() [][]int {
+	space := e.sim.Physics.GetSpace()
+	cellW := float64(ui.ScreenWidth) / float64(e.cfg.GridWidth)
+	cellH := float64(ui.ScreenHeight) / float64(e.cfg.GridHeight)
+
+	grid := make([][]int, e.cfg.GridHeight)
+	for row := range grid {
+		grid[row] = make([]int, e.cfg.GridWidth)
+		for col := range grid[row] {
+			at := cp.Vector{X: (float64(col) + 0.5) * cellW, Y: (float64(row) + 0.5) * cellH}
+			if info := space.PointQueryNearest(at, 0, cp.SHAPE_FILTER_ALL); info.Shape != nil {
+				if kind, ok := info.Shape.Body().UserData.(assets.Kind); ok {
+					grid[row][col] = int(kind)
+				}
+			}
+		}
+	}
+	return grid
+}
@@ -0,0 +1,94 @@
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+)
+
+type Locale string
+
+const (
+	English  Locale = "en"
+	Japanese Locale = "ja"
+
+	DefaultLocale = English
+)
+
+//go:embed locales/*.json
+var localeFS embed.FS
+
+var (
+	catalogues = map[Locale]map[string]string{
+		English:  loadCatalogue(English),
+		Japanese: loadCatalogue(Japanese),
+	}
+	current = DefaultLocale
+)
+
+func loadCatalogue(locale Locale) map[string]string {
+	data, err := localeFS.ReadFile("locales/" + string(locale) + ".json")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	messages := make(map[string]string)
+	if err := json.Unmarshal(data, &messages); err != nil {
+		log.Fatalf("locale %s: %v", locale, err)
+	}
+	return messages
+}
+
+func Locales() []Locale {
+	return []Locale{English, Japanese}
+}
+
+func Catalogue(locale Locale) map[string]string {
+	return catalogues[locale]
+}
+
+func SetLocale(locale Locale) {
+	if _, ok := catalogues[locale]; ok {
+		current = locale
+	}
+}
+
+func CurrentLocale() Locale {
+	return current
+}
+
+func ParseLocale(tag string) Locale {
+	tag = strings.ToLower(tag)
+	for _, locale := range Locales() {
+		if strings.HasPrefix(tag, string(locale)) {
+			return locale
+		}
+	}
+	return DefaultLocale
+}
+
+func DetectFromEnv() Locale {
+	for _, key := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if v := os.Getenv(key); v != "" && v != "C" && v != "POSIX" {
+			return ParseLocale(v)
+		}
+	}
+	return DefaultLocale
+}
+
+func T(key string) string {
+	if msg, ok := catalogues[current][key]; ok {
+		return msg
+	}
+	if msg, ok := catalogues[DefaultLocale][key]; ok {
+		return msg
+	}
+	return key
+}
+
+func Tf(key string, args ...interface{}) string {
+	return fmt.Sprintf(T(key), args...)
+}
@@ -1,6 +1,8 @@
 package input
 
 import (
+	"sort"
+
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/inpututil"
 	"github.com/ponyo877/suika-shaker/internal/ui"
@@ -8,6 +10,12 @@ import (
 
 type Handler struct{}
 
+// versusKeys splits the keyboard between two players: left, right and shake.
+var versusKeys = [2][3]ebiten.Key{
+	{ebiten.KeyA, ebiten.KeyD, ebiten.KeyW},
+	{ebiten.KeyArrowLeft, ebiten.KeyArrowRight, ebiten.KeyArrowUp},
+}
+
 func NewHandler() *Handler {
 	return &Handler{}
 }
@@ -17,8 +25,7 @@ func (h *Handler) IsButtonClicked(x, y int, cfg ui.ButtonConfig) bool {
 		y >= int(cfg.Y) && y <= int(cfg.Y+cfg.Height)
 }
 
-func (h *Handler) IsRetryButtonClicked(x, y int) bool {
-	cfg := ui.NewDialogConfig()
+func (h *Handler) IsRetryButtonClicked(x, y int, cfg ui.DialogConfig) bool {
 	return x >= int(cfg.RetryX) && x <= int(cfg.RetryX+cfg.RetryWidth) &&
 		y >= int(cfg.ButtonY) && y <= int(cfg.ButtonY+cfg.RetryHeight)
 }
@@ -31,6 +38,51 @@ func (h *Handler) CheckMouseClick() (bool, int, int) {
 	return false, 0, 0
 }
 
+func (h *Handler) CheckThemeToggle() bool {
+	return inpututil.IsKeyJustPressed(ebiten.KeyT)
+}
+
+func (h *Handler) CheckStartKey() bool {
+	return inpututil.IsKeyJustPressed(ebiten.KeyEnter) || inpututil.IsKeyJustPressed(ebiten.KeySpace)
+}
+
+// VersusControl reads a versus player's tilt in [-1, 1] and shake from their
+// half of the keyboard and, if connected, their gamepad.
+func (h *Handler) VersusControl(player int) (float64, bool) {
+	keys := versusKeys[player]
+	tilt := 0.0
+	if ebiten.IsKeyPressed(keys[0]) {
+		tilt--
+	}
+	if ebiten.IsKeyPressed(keys[1]) {
+		tilt++
+	}
+	shake := ebiten.IsKeyPressed(keys[2])
+
+	gamepads := ebiten.AppendGamepadIDs(nil)
+	sort.Slice(gamepads, func(i, j int) bool { return gamepads[i] < gamepads[j] })
+	if player < len(gamepads) {
+		id := gamepads[player]
+		if ebiten.IsStandardGamepadLayoutAvailable(id) {
+			tilt += ebiten.StandardGamepadAxisValue(id, ebiten.StandardGamepadAxisLeftStickHorizontal)
+			shake = shake || ebiten.IsStandardGamepadButtonPressed(id, ebiten.StandardGamepadButtonRightBottom)
+		} else if ebiten.GamepadAxisCount(id) > 0 {
+			tilt += ebiten.GamepadAxisValue(id, 0)
+			shake = shake || ebiten.IsGamepadButtonPressed(id, ebiten.GamepadButton0)
+		}
+	}
+	return tilt, shake
+}
+
+func (h *Handler) Pointer() (int, int, bool) {
+	if touchIDs := ebiten.AppendTouchIDs(nil); len(touchIDs) > 0 {
+		x, y := ebiten.TouchPosition(touchIDs[0])
+		return x, y, true
+	}
+	x, y := ebiten.CursorPosition()
+	return x, y, ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft)
+}
+
 func (h *Handler) CheckTouchInput() []struct{ X, Y int } {
 	touchIDs := inpututil.AppendJustPressedTouchIDs(nil)
 	var touches []struct{ X, Y int }
@@ -0,0 +1,170 @@
+package level
+
+import (
+	"embed"
+	"encoding/json"
+	"log"
+	"path"
+	"sort"
+
+	assets "github.com/ponyo877/suika-shaker/assets/image"
+	"github.com/ponyo877/suika-shaker/internal/gamestate"
+	"github.com/ponyo877/suika-shaker/internal/mode"
+	"github.com/ponyo877/suika-shaker/internal/storage"
+)
+
+const (
+	MaxStars        = 3
+	progressKey     = "levels.json"
+	defaultInterval = 45
+)
+
+//go:embed levels/*.json
+var levelFS embed.FS
+
+var levels = loadLevels()
+
+// Body is a fruit already on the board when a level starts, in screen
+// coordinates.
+type Body struct {
+	Kind  assets.Kind `json:"kind"`
+	X     float64     `json:"x"`
+	Y     float64     `json:"y"`
+	Angle float64     `json:"angle,omitempty"`
+}
+
+const (
+	ObjectiveMake  = "make"
+	ObjectiveClear = "clear"
+	ObjectiveScore = "score"
+)
+
+// Objective is what a level asks for: make Count fruits of Kind, clear every
+// fruit of Kind from the board, or reach Score points.
+type Objective struct {
+	Type  string      `json:"type"`
+	Kind  assets.Kind `json:"kind,omitempty"`
+	Count int         `json:"count,omitempty"`
+	Score int         `json:"score,omitempty"`
+}
+
+type Level struct {
+	ID           string        `json:"id"`
+	Seed         int64         `json:"seed"`
+	DropInterval int           `json:"drop_interval,omitempty"`
+	Board        []Body        `json:"board"`
+	Queue        []assets.Kind `json:"queue"`
+	Objective    Objective     `json:"objective"`
+	// Stars holds the most drops that still earn three and two stars.
+	Stars [2]int `json:"stars"`
+}
+
+func loadLevels() []Level {
+	entries, err := levelFS.ReadDir("levels")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var loaded []Level
+	for _, e := range entries {
+		data, err := levelFS.ReadFile(path.Join("levels", e.Name()))
+		if err != nil {
+			log.Fatal(err)
+		}
+		var l Level
+		if err := json.Unmarshal(data, &l); err != nil {
+			log.Fatalf("level %s: %v", e.Name(), err)
+		}
+		loaded = append(loaded, l)
+	}
+	sort.Slice(loaded, func(i, j int) bool { return loaded[i].ID < loaded[j].ID })
+	return loaded
+}
+
+// All returns the levels in the order they unlock.
+func All() []Level {
+	return append([]Level(nil), levels...)
+}
+
+// Mode returns the rules every level is played under.
+func Mode() mode.Mode {
+	return mode.Mode{
+		Name:         mode.Puzzle,
+		Label:        "mode_puzzle",
+		DropInterval: defaultInterval,
+		Scored:       true,
+	}
+}
+
+func (l Level) Mode() mode.Mode {
+	m := Mode()
+	if l.DropInterval > 0 {
+		m.DropInterval = l.DropInterval
+	}
+	return m
+}
+
+// Met reports whether the objective is complete; count returns how many
+// fruits of a kind are on the board.
+func (o Objective) Met(state *gamestate.State, count func(assets.Kind) int) bool {
+	switch o.Type {
+	case ObjectiveMake:
+		return state.Created[o.Kind] >= o.Count
+	case ObjectiveClear:
+		return count(o.Kind) == 0
+	case ObjectiveScore:
+		return state.Score >= o.Score
+	}
+	return false
+}
+
+func (l Level) StarsFor(drops int) int {
+	switch {
+	case drops <= l.Stars[0]:
+		return 3
+	case drops <= l.Stars[1]:
+		return 2
+	}
+	return 1
+}
+
+// Progress maps level IDs to the best star rating earned.
+type Progress map[string]int
+
+func LoadProgress() Progress {
+	p := Progress{}
+	if data, ok := storage.Load(progressKey); ok {
+		if err := json.Unmarshal([]byte(data), &p); err != nil {
+			log.Printf("failed to read level progress: %v", err)
+		}
+	}
+	return p
+}
+
+// Record keeps stars for id if they beat the previous best and saves the
+// result.
+func (p Progress) Record(id string, stars int) error {
+	if stars <= p[id] {
+		return nil
+	}
+	p[id] = stars
+	data, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+	return storage.Save(progressKey, string(data))
+}
+
+// Unlocked reports whether the i-th level can be played: the first always
+// can, the rest once the level before has at least one star.
+func (p Progress) Unlocked(i int) bool {
+	return i == 0 || (i < len(levels) && p[levels[i-1].ID] > 0)
+}
+
+func (p Progress) TotalStars() int {
+	total := 0
+	for _, stars := range p {
+		total += stars
+	}
+	return total
+}
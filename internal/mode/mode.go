@@ -0,0 +1,161 @@
+package mode
+
+const (
+	Classic    = "classic"
+	TimeAttack = "time-attack"
+	Zen        = "zen"
+	Endless    = "endless"
+	Daily      = "daily"
+	Puzzle     = "puzzle"
+	Versus     = "versus"
+	Online     = "online"
+)
+
+const ticksPerSecond = 60
+
+// Spawn zones say where the next fruit may appear.
+const (
+	SpawnAnywhere = ""     // anywhere on the board
+	SpawnTop      = "top"  // along the top of the board
+	SpawnAway     = "away" // along the top, on the side away from the heaviest pile
+)
+
+// SpawnStage is a spawn table that takes over once a round reaches a score
+// or a time, whichever is set.
+type SpawnStage struct {
+	FromScore int   `json:"from_score,omitempty"`
+	FromTick  int   `json:"from_tick,omitempty"`
+	Weights   []int `json:"weights"` // relative odds of each kind, from the grape up
+}
+
+// Mode holds the rules that differ between ways of playing: how often fruits
+// drop, what ends a round and whether the score counts towards a high score.
+type Mode struct {
+	Name  string `json:"name"`
+	Label string `json:"label"` // i18n key
+
+	DropInterval    int `json:"drop_interval"`               // ticks between drops at the start of a round
+	MinDropInterval int `json:"min_drop_interval,omitempty"` // floor for DropInterval when RampEvery is set
+	RampEvery       int `json:"ramp_every,omitempty"`        // ticks after which DropInterval shrinks by one; 0 keeps it fixed
+
+	TimeLimit      int  `json:"time_limit,omitempty"`      // ticks until the round ends; 0 means no limit
+	NoGameOver     bool `json:"no_game_over,omitempty"`    // overflowing fruits are cleared instead of ending the round
+	RecoverEscapes bool `json:"recover_escapes,omitempty"` // fruits knocked out of the container are put back instead of ending the round
+	Scored         bool `json:"scored"`                    // the score is shown and counts towards the mode's high score
+
+	ArenaWidth     float64 `json:"arena_width,omitempty"`     // width of the container; 0 uses the whole screen
+	StartingFruits int     `json:"starting_fruits,omitempty"` // small fruits already on the board when the round starts
+
+	SpawnWeights []int        `json:"spawn_weights,omitempty"` // relative odds of each kind being dropped, from the grape up; empty drops grapes and mandarins evenly
+	SpawnTable   []SpawnStage `json:"spawn_table,omitempty"`   // stages replacing SpawnWeights as the round goes on, in order
+	SpawnZone    string       `json:"spawn_zone,omitempty"`    // where fruits appear; see SpawnTop and SpawnAway
+	GravityScale float64      `json:"gravity_scale,omitempty"` // multiplies the tilt gravity; 0 leaves it as is
+	PowerUps     int          `json:"power_ups,omitempty"`     // percentage of drops that are a power-up instead of a fruit
+}
+
+// classicSpawns brings apples and then pears into the drops as the score
+// grows, so a long round does not play like its first minute.
+var classicSpawns = []SpawnStage{
+	{FromScore: 1500, Weights: []int{4, 4, 1}},
+	{FromScore: 4000, Weights: []int{3, 4, 2, 1}},
+}
+
+var modes = []Mode{
+	{
+		Name:            Classic,
+		Label:           "mode_classic",
+		DropInterval:    45,
+		MinDropInterval: 25,
+		RampEvery:       20 * ticksPerSecond,
+		RecoverEscapes:  true,
+		Scored:          true,
+		SpawnTable:      classicSpawns,
+		SpawnZone:       SpawnAway,
+	},
+	{
+		Name:            TimeAttack,
+		Label:           "mode_time_attack",
+		DropInterval:    45,
+		MinDropInterval: 30,
+		RampEvery:       8 * ticksPerSecond,
+		TimeLimit:       120 * ticksPerSecond,
+		Scored:          true,
+		SpawnZone:       SpawnTop,
+	},
+	{
+		Name:         Zen,
+		Label:        "mode_zen",
+		DropInterval: 60,
+		NoGameOver:   true,
+		PowerUps:     8,
+	},
+	{
+		Name:            Endless,
+		Label:           "mode_endless",
+		DropInterval:    45,
+		MinDropInterval: 15,
+		RampEvery:       10 * ticksPerSecond,
+		RecoverEscapes:  true,
+		Scored:          true,
+		SpawnTable:      classicSpawns,
+		SpawnZone:       SpawnAway,
+		PowerUps:        6,
+	},
+}
+
+func Default() Mode {
+	return modes[0]
+}
+
+// All returns the modes in the order they are offered on the title screen.
+func All() []Mode {
+	return append([]Mode(nil), modes...)
+}
+
+func Lookup(name string) (Mode, bool) {
+	for _, m := range modes {
+		if m.Name == name {
+			return m, true
+		}
+	}
+	return Mode{}, false
+}
+
+// DropIntervalAt returns the number of ticks between drops tick ticks into a
+// round.
+func (m Mode) DropIntervalAt(tick int) int {
+	if m.RampEvery <= 0 {
+		return m.DropInterval
+	}
+	return max(m.MinDropInterval, m.DropInterval-tick/m.RampEvery)
+}
+
+// SpawnWeightsAt returns the spawn table in force tick ticks into a round
+// with score points: the last stage reached, or SpawnWeights before any.
+func (m Mode) SpawnWeightsAt(tick, score int) []int {
+	weights := m.SpawnWeights
+	for _, stage := range m.SpawnTable {
+		if score >= stage.FromScore && tick >= stage.FromTick {
+			weights = stage.Weights
+		}
+	}
+	return weights
+}
+
+// Gravity scales a gravity input by the mode's GravityScale.
+func (m Mode) Gravity(x, y int) (float64, float64) {
+	scale := m.GravityScale
+	if scale == 0 {
+		scale = 1
+	}
+	return float64(x) * scale, float64(y) * scale
+}
+
+// Remaining returns the ticks left before the time limit, or -1 if the mode
+// has none.
+func (m Mode) Remaining(tick int) int {
+	if m.TimeLimit <= 0 {
+		return -1
+	}
+	return max(0, m.TimeLimit-tick)
+}
@@ -0,0 +1,104 @@
+package online
+
+import (
+	"context"
+	"sync"
+
+	"github.com/coder/websocket"
+	"github.com/coder/websocket/wsjson"
+)
+
+// Client is a connection to a room. It connects and reads in the background
+// and buffers what arrives until the game loop polls for it, so a slow
+// network never stalls a frame.
+type Client struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	out    chan Message
+
+	mu    sync.Mutex
+	inbox []Message
+	err   error
+}
+
+// Connect starts joining the room at url, as built by RoomURL.
+func Connect(url string) *Client {
+	ctx, cancel := context.WithCancel(context.Background())
+	c := &Client{ctx: ctx, cancel: cancel, out: make(chan Message, 64)}
+	go c.run(url)
+	return c
+}
+
+func (c *Client) run(url string) {
+	conn, _, err := websocket.Dial(c.ctx, url, dialOptions(url))
+	if err != nil {
+		c.fail(err)
+		return
+	}
+	defer conn.CloseNow()
+
+	go func() {
+		for {
+			select {
+			case <-c.ctx.Done():
+				conn.Close(websocket.StatusNormalClosure, "")
+				return
+			case m := <-c.out:
+				if err := wsjson.Write(c.ctx, conn, m); err != nil {
+					c.fail(err)
+					return
+				}
+			}
+		}
+	}()
+
+	for {
+		var m Message
+		if err := wsjson.Read(c.ctx, conn, &m); err != nil {
+			c.fail(err)
+			return
+		}
+		c.mu.Lock()
+		c.inbox = append(c.inbox, m)
+		c.mu.Unlock()
+	}
+}
+
+func (c *Client) fail(err error) {
+	c.mu.Lock()
+	if c.err == nil && c.ctx.Err() == nil {
+		c.err = err
+	}
+	c.mu.Unlock()
+	c.cancel()
+}
+
+// Send queues m for the server. Messages are dropped rather than blocking
+// when the connection has fallen far behind.
+func (c *Client) Send(m Message) {
+	select {
+	case c.out <- m:
+	default:
+	}
+}
+
+// Poll returns the messages received since the last call.
+func (c *Client) Poll() []Message {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	inbox := c.inbox
+	c.inbox = nil
+	return inbox
+}
+
+// Err returns why the connection failed, or nil while it is open or after
+// Close.
+func (c *Client) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err
+}
+
+func (c *Client) Close() {
+	c.cancel()
+}
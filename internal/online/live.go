@@ -0,0 +1,40 @@
+package online
+
+import "fmt"
+
+// A live stream relays one client's board, tick by tick, to any number of
+// watchers. The player publishes TypeFrame messages and watchers receive the
+// same messages, followed by TypeEnd when the player disconnects.
+
+// LivePath lists the streams being published.
+const LivePath = "/api/live"
+
+func PublishPath(stream string) string {
+	return LivePath + "/" + stream + "/publish"
+}
+
+func WatchPath(stream string) string {
+	return LivePath + "/" + stream + "/watch"
+}
+
+// PublishURL is the WebSocket URL a player streams their board to.
+func PublishURL(baseURL, stream string) (string, error) {
+	if !ValidName(stream) {
+		return "", fmt.Errorf("invalid stream name %q", stream)
+	}
+	return socketURL(baseURL, PublishPath(stream), nil)
+}
+
+// WatchURL is the WebSocket URL spectators receive a stream's boards from.
+func WatchURL(baseURL, stream string) (string, error) {
+	if !ValidName(stream) {
+		return "", fmt.Errorf("invalid stream name %q", stream)
+	}
+	return socketURL(baseURL, WatchPath(stream), nil)
+}
+
+// Stream describes a stream being published, as listed at LivePath.
+type Stream struct {
+	Name     string `json:"name"`
+	Watchers int    `json:"watchers"`
+}
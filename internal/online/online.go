@@ -0,0 +1,151 @@
+// Package online is the protocol spoken between the game and the rooms hub in
+// cmd/server: players join a room over a WebSocket, the server starts a round
+// for everyone at once, and each client streams its board while it plays its
+// own copy of the seeded game.
+package online
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	assets "github.com/ponyo877/suika-shaker/assets/image"
+	"github.com/ponyo877/suika-shaker/internal/mode"
+	"github.com/ponyo877/suika-shaker/internal/snapshot"
+)
+
+const (
+	MinPlayers = 2
+	MaxPlayers = 8
+
+	// DefaultDuration is how long a round lasts unless the server says otherwise.
+	DefaultDuration = 2 * time.Minute
+
+	// ProgressInterval is how often, in ticks, a client reports its board.
+	ProgressInterval = 30
+
+	roomsPath      = "/api/rooms/"
+	ticksPerSecond = 60
+)
+
+// Message types. Clients send start, progress and result; everything else
+// comes from the server.
+const (
+	TypeWelcome  = "welcome"  // the player's own id
+	TypeRoom     = "room"     // room state and the players in it
+	TypeStart    = "start"    // request a round, or the round's seed and start time
+	TypeProgress = "progress" // a board snapshot, relayed to the other players
+	TypeEnd      = "end"      // the round's time is up
+	TypeResult   = "result"   // a player's final score and replay
+	TypeResults  = "results"  // the final standings
+	TypeFrame    = "frame"    // one tick of a spectator stream
+	TypeError    = "error"
+)
+
+// Room states.
+const (
+	Waiting = "waiting"
+	Playing = "playing"
+)
+
+// Message is the single envelope for every message in either direction;
+// only the fields relevant to its Type are set.
+type Message struct {
+	Type     string             `json:"type"`
+	Player   int                `json:"player,omitempty"`
+	Room     string             `json:"room,omitempty"`
+	State    string             `json:"state,omitempty"`
+	Players  []Player           `json:"players,omitempty"`
+	Seed     int64              `json:"seed,omitempty"`
+	StartIn  int64              `json:"start_in_ms,omitempty"` // relative, so clients need not share the server's clock
+	Duration int                `json:"duration,omitempty"`    // seconds
+	Board    *snapshot.Snapshot `json:"board,omitempty"`
+	Score    int                `json:"score,omitempty"`
+	Replay   json.RawMessage    `json:"replay,omitempty"`
+	Error    string             `json:"error,omitempty"`
+}
+
+type Player struct {
+	ID       int    `json:"id"`
+	Name     string `json:"name"`
+	Score    int    `json:"score"`
+	Finished bool   `json:"finished,omitempty"`
+	Rejected bool   `json:"rejected,omitempty"` // the server did not accept the player's result
+}
+
+// Mode is a timed race: classic drops for a fixed time, highest score wins.
+func Mode(duration time.Duration) mode.Mode {
+	m, _ := mode.Lookup(mode.TimeAttack)
+	m.Name = mode.Online
+	m.Label = "mode_online"
+	m.TimeLimit = int(duration.Seconds()) * ticksPerSecond
+	return m
+}
+
+// MaxScore bounds the score a board can reach after ticks. Every merge
+// removes at least one fruit, so there are no more merges than fruits that
+// have entered the board, and none is worth more than MaxMergeScore.
+func MaxScore(m mode.Mode, ticks int) int {
+	interval := m.DropInterval
+	if m.RampEvery > 0 {
+		interval = m.MinDropInterval
+	}
+	fruits := m.StartingFruits + ticks/max(1, interval) + 1
+	return fruits * MaxMergeScore()
+}
+
+// MaxMergeScore is the most a single merge is worth with the fruit table in
+// force.
+func MaxMergeScore() int {
+	best := 0
+	assets.ForEach(func(_ assets.Kind, set assets.ImageSet) {
+		best = max(best, set.Score)
+	})
+	return best
+}
+
+// RoomPath is the WebSocket endpoint for a room on the server.
+func RoomPath(room string) string {
+	return roomsPath + room
+}
+
+// ValidName reports whether name can be used to name a room or stream: 1 to
+// 32 letters, digits, '-' or '_'.
+func ValidName(name string) bool {
+	if name == "" || len(name) > 32 {
+		return false
+	}
+	for _, c := range name {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_') {
+			return false
+		}
+	}
+	return true
+}
+
+// RoomURL turns the server's base URL into the WebSocket URL for joining
+// room as name.
+func RoomURL(baseURL, room, name string) (string, error) {
+	if !ValidName(room) {
+		return "", fmt.Errorf("invalid room name %q", room)
+	}
+	return socketURL(baseURL, RoomPath(room), url.Values{"name": {name}})
+}
+
+func socketURL(baseURL, path string, query url.Values) (string, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return "", err
+	}
+	switch u.Scheme {
+	case "https":
+		u.Scheme = "wss"
+	case "http":
+		u.Scheme = "ws"
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/") + path
+	u.RawQuery = query.Encode()
+	return u.String(), nil
+}
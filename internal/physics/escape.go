@@ -0,0 +1,54 @@
+package physics
+
+import (
+	"github.com/jakecoffman/cp/v2"
+)
+
+// MaxFruitSpeed caps how fast a fruit may move, in pixels per second. At 60
+// steps a second it keeps a fruit from covering more than a wall's thickness
+// in one step, however hard the board is shaken.
+const MaxFruitSpeed = 1200
+
+// clampedUpdatePosition integrates a fruit's position after capping its
+// velocity, which by then includes the impulses of this step's collisions.
+func clampedUpdatePosition(body *cp.Body, dt float64) {
+	if v := body.Velocity(); v.LengthSq() > MaxFruitSpeed*MaxFruitSpeed {
+		body.SetVelocityVector(v.Clamp(MaxFruitSpeed))
+	}
+	cp.BodyUpdatePosition(body, dt)
+}
+
+// Escaped returns the fruits whose centres are outside the container.
+func (m *Manager) Escaped() []*cp.Body {
+	var escaped []*cp.Body
+	m.space.EachBody(func(body *cp.Body) {
+		if body.UserData == nil {
+			return
+		}
+		p := body.Position()
+		if p.X < m.left || p.X > m.right || p.Y < 0 || p.Y > BoardHeight {
+			escaped = append(escaped, body)
+		}
+	})
+	return escaped
+}
+
+// MoveFruit puts a fruit at pos, at rest. It must not be called while the
+// space is stepping.
+func (m *Manager) MoveFruit(body *cp.Body, pos cp.Vector) {
+	body.SetPosition(pos)
+	body.SetVelocity(0, 0)
+	body.SetAngularVelocity(0)
+	body.EachShape(m.space.ReindexShape)
+}
+
+// RemoveBody removes a fruit and its shapes straight away. It must not be
+// called while the space is stepping.
+func (m *Manager) RemoveBody(body *cp.Body) {
+	var shapes []*cp.Shape
+	body.EachShape(func(shape *cp.Shape) { shapes = append(shapes, shape) })
+	for _, shape := range shapes {
+		m.space.RemoveShape(shape)
+	}
+	m.space.RemoveBody(body)
+}
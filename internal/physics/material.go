@@ -0,0 +1,65 @@
+package physics
+
+import (
+	assets "github.com/ponyo877/suika-shaker/assets/image"
+)
+
+type Material struct {
+	Elasticity float64
+	Friction   float64
+	MassFactor float64
+}
+
+type Wall int
+
+const (
+	LeftWall Wall = iota
+	RightWall
+	FloorWall
+	CeilingWall
+)
+
+var (
+	DefaultFruitMaterial = Material{Elasticity: FruitElasticity, Friction: FruitFriction, MassFactor: FruitMassFactor}
+	DefaultWallMaterial  = Material{Elasticity: WallElasticity, Friction: WallFriction}
+
+	fruitMaterials = map[assets.Kind]Material{
+		assets.Grape:      {Elasticity: 0.5, Friction: 0.7, MassFactor: 0.0008},
+		assets.Mandarin:   {Elasticity: 0.3, Friction: 0.8, MassFactor: 0.001},
+		assets.Apple:      {Elasticity: 0.25, Friction: 0.9, MassFactor: 0.001},
+		assets.Pear:       {Elasticity: 0.2, Friction: 0.9, MassFactor: 0.0011},
+		assets.Peach:      {Elasticity: 0.15, Friction: 0.3, MassFactor: 0.001},
+		assets.Pineapple:  {Elasticity: 0.1, Friction: 1.0, MassFactor: 0.0012},
+		assets.Melon:      {Elasticity: 0.1, Friction: 0.9, MassFactor: 0.0014},
+		assets.Watermelon: {Elasticity: 0.05, Friction: 0.9, MassFactor: 0.0018},
+	}
+
+	wallMaterials = map[Wall]Material{
+		LeftWall:    DefaultWallMaterial,
+		RightWall:   DefaultWallMaterial,
+		FloorWall:   {Elasticity: 0.3, Friction: 0.8},
+		CeilingWall: DefaultWallMaterial,
+	}
+)
+
+func FruitMaterial(kind assets.Kind) Material {
+	if m, ok := fruitMaterials[kind]; ok {
+		return m
+	}
+	return DefaultFruitMaterial
+}
+
+func SetFruitMaterial(kind assets.Kind, m Material) {
+	fruitMaterials[kind] = m
+}
+
+func WallMaterial(wall Wall) Material {
+	if m, ok := wallMaterials[wall]; ok {
+		return m
+	}
+	return DefaultWallMaterial
+}
+
+func SetWallMaterial(wall Wall, m Material) {
+	wallMaterials[wall] = m
+}
@@ -3,15 +3,20 @@ package physics
 import (
 	"github.com/jakecoffman/cp/v2"
 	assets "github.com/ponyo877/suika-shaker/assets/image"
-	"github.com/ponyo877/suika-shaker/internal/ui"
+)
+
+// The board the simulation runs on, in pixels. It is the game's screen, but
+// is kept here so that the simulation builds without any graphics.
+const (
+	BoardWidth  = 480
+	BoardHeight = 800
 )
 
 const (
-	SpawnCheckRadius  = 40
 	MaxSpawnFailures  = 3
 	ContainerHeight   = 800
 	PaddingBottom     = 0
-	WallThickness     = 1
+	WallThickness     = 20
 	WallElasticity    = 0.6
 	WallFriction      = 0.4
 	FruitElasticity   = 0.2
@@ -24,35 +29,64 @@ const (
 
 type Manager struct {
 	space *cp.Space
+	left  float64
+	right float64
 }
 
 func NewManager() *Manager {
+	return NewArenaManager(0)
+}
+
+// ArenaBounds returns the x coordinates of the side walls for a container
+// width wide, centred on the screen; width 0 uses the whole screen.
+func ArenaBounds(width float64) (float64, float64) {
+	if width <= 0 || width > BoardWidth {
+		return 0, BoardWidth
+	}
+	return (BoardWidth - width) / 2, (BoardWidth + width) / 2
+}
+
+// NewArenaManager is NewManager with the side walls moved in to make the
+// container width wide.
+func NewArenaManager(width float64) *Manager {
+	left, right := ArenaBounds(width)
+
 	space := cp.NewSpace()
 	space.Iterations = SpaceIterations
 	space.SetGravity(cp.Vector{X: 0, Y: DefaultGravityY})
 	space.SleepTimeThreshold = SleepTimeThreshold
 	space.SetDamping(1)
 
-	walls := []cp.Vector{
-		{X: 0, Y: 0}, {X: 0, Y: ui.ScreenHeight},
-		{X: ui.ScreenWidth, Y: 0}, {X: ui.ScreenWidth, Y: ui.ScreenHeight},
-		{X: 0, Y: ui.ScreenHeight}, {X: ui.ScreenWidth, Y: ui.ScreenHeight},
-		{X: 0, Y: 0}, {X: ui.ScreenWidth, Y: 0},
+	// The walls are thick segments set back by their radius, so their inner
+	// faces sit on the container's edges while a fast fruit still meets
+	// solid wall rather than a line it can step over.
+	const t = WallThickness
+	walls := map[Wall][2]cp.Vector{
+		LeftWall:    {{X: left - t, Y: -t}, {X: left - t, Y: BoardHeight + t}},
+		RightWall:   {{X: right + t, Y: -t}, {X: right + t, Y: BoardHeight + t}},
+		FloorWall:   {{X: -t, Y: BoardHeight + t}, {X: BoardWidth + t, Y: BoardHeight + t}},
+		CeilingWall: {{X: -t, Y: -t}, {X: BoardWidth + t, Y: -t}},
 	}
 
-	for i := 0; i < len(walls)-1; i += 2 {
-		shape := space.AddShape(cp.NewSegment(space.StaticBody, walls[i], walls[i+1], WallThickness))
-		shape.SetElasticity(WallElasticity)
-		shape.SetFriction(WallFriction)
+	for _, wall := range []Wall{LeftWall, RightWall, FloorWall, CeilingWall} {
+		ends := walls[wall]
+		material := WallMaterial(wall)
+		shape := space.AddShape(cp.NewSegment(space.StaticBody, ends[0], ends[1], WallThickness))
+		shape.SetElasticity(material.Elasticity)
+		shape.SetFriction(material.Friction)
 	}
 
-	return &Manager{space: space}
+	return &Manager{space: space, left: left, right: right}
 }
 
 func (m *Manager) GetSpace() *cp.Space {
 	return m.space
 }
 
+func (m *Manager) Bounds() (float64, float64) {
+	return m.left, m.right
+}
+
 func (m *Manager) SetGravity(x, y float64) {
 	m.space.SetGravity(cp.Vector{X: x, Y: y})
 }
@@ -61,25 +95,30 @@ func (m *Manager) Step(dt float64) {
 	m.space.Step(dt)
 }
 
-func (m *Manager) AddFruit(kind assets.Kind, position cp.Vector, angle float64) {
+// AddFruit adds a fruit of kind to the space and returns its body, or nil if
+// kind is unknown.
+func (m *Manager) AddFruit(kind assets.Kind, position cp.Vector, angle float64) *cp.Body {
 	if !assets.Exists(kind) {
-		return
+		return nil
 	}
 	imgSet := assets.Get(kind)
+	material := FruitMaterial(kind)
 
 	body := m.space.AddBody(cp.NewBody(0, cp.MomentForPoly(10, len(imgSet.Vectors), imgSet.Vectors, cp.Vector{}, 1)))
 	body.SetPosition(position)
 	body.SetAngle(angle)
+	body.SetPositionUpdateFunc(clampedUpdatePosition)
 	body.UserData = kind
 
 	fruit := m.space.AddShape(cp.NewPolyShape(body, len(imgSet.Vectors), imgSet.Vectors, cp.NewTransformIdentity(), 0))
-	body.SetMass(fruit.Area() * FruitMassFactor)
-	fruit.SetElasticity(FruitElasticity)
-	fruit.SetFriction(FruitFriction)
+	body.SetMass(fruit.Area() * material.MassFactor)
+	fruit.SetElasticity(material.Elasticity)
+	fruit.SetFriction(material.Friction)
 	fruit.SetCollisionType(cp.CollisionType(kind))
 
 	body.Activate()
 	m.space.ReindexShape(fruit)
+	return body
 }
 
 func (m *Manager) RemoveFruit(shape *cp.Shape) {
@@ -87,14 +126,6 @@ func (m *Manager) RemoveFruit(shape *cp.Shape) {
 	m.space.RemoveShape(shape)
 }
 
-func (m *Manager) CanSpawnAt(x, y, radius float64) bool {
-	info := m.space.PointQueryNearest(cp.Vector{X: x, Y: y}, radius, cp.ShapeFilter{})
-	if info.Shape == nil {
-		return true
-	}
-	return info.Shape.Body().UserData == nil
-}
-
 func (m *Manager) StopAllBodies() {
 	m.space.EachBody(func(body *cp.Body) {
 		if body.UserData != nil {
@@ -116,17 +147,95 @@ func (m *Manager) ScheduleRemoveAllFruits() {
 	})
 }
 
+func (m *Manager) CountFruits(kind assets.Kind) int {
+	count := 0
+	m.space.EachBody(func(body *cp.Body) {
+		if k, ok := body.UserData.(assets.Kind); ok && k == kind {
+			count++
+		}
+	})
+	return count
+}
+
+// Centroid returns the mass-weighted centre of the fruits in play, and false
+// when there are none.
+func (m *Manager) Centroid() (cp.Vector, bool) {
+	var sum cp.Vector
+	total := 0.0
+	m.space.EachBody(func(body *cp.Body) {
+		if body.UserData == nil {
+			return
+		}
+		sum = sum.Add(body.Position().Mult(body.Mass()))
+		total += body.Mass()
+	})
+	if total == 0 {
+		return cp.Vector{}, false
+	}
+	return sum.Mult(1 / total), true
+}
+
+// FilledArea returns the total area of the fruits in play.
+func (m *Manager) FilledArea() float64 {
+	area := 0.0
+	m.space.EachShape(func(shape *cp.Shape) {
+		if shape.Body().UserData != nil {
+			area += shape.Area()
+		}
+	})
+	return area
+}
+
 func (m *Manager) CheckBodiesOutOfBounds() bool {
 	outOfBounds := false
 	m.space.EachBody(func(body *cp.Body) {
 		x, y := body.Position().X, body.Position().Y
-		if x < 0 || x > ui.ScreenWidth || y < 0 || y > ui.ScreenHeight {
+		if x < 0 || x > BoardWidth || y < 0 || y > BoardHeight {
 			outOfBounds = true
 		}
 	})
 	return outOfBounds
 }
 
+func (m *Manager) ScheduleRemoveFruitsOutOfBounds() {
+	m.space.EachShape(func(shape *cp.Shape) {
+		if shape.Body().UserData == nil {
+			return
+		}
+		x, y := shape.Body().Position().X, shape.Body().Position().Y
+		if x < 0 || x > BoardWidth || y < 0 || y > BoardHeight {
+			m.space.AddPostStepCallback(CreateRemoveShapeCallback(m), shape, nil)
+		}
+	})
+}
+
+// ScheduleRemoveFruits removes the shapes once the space has finished
+// stepping. Removals are keyed by shape, so if any of them is already going
+// nothing is scheduled and it returns false: a fruit is used up at most once
+// per step, however many shapes it touches.
+func (m *Manager) ScheduleRemoveFruits(shapes ...*cp.Shape) bool {
+	for _, shape := range shapes {
+		if m.space.PostStepCallback(shape) != nil {
+			return false
+		}
+	}
+	for _, shape := range shapes {
+		m.space.AddPostStepCallback(CreateRemoveShapeCallback(m), shape, nil)
+	}
+	return true
+}
+
+// ScheduleAddFruit adds a fruit once the space has finished stepping. The
+// callback has no key, since chipmunk drops a callback whose key is already
+// waiting and two adds in one step must both happen.
+func (m *Manager) ScheduleAddFruit(kind assets.Kind, pos cp.Vector, angle float64) {
+	m.space.AddPostStepCallback(CreateAddShapeCallback(m), nil, AddShapeData{
+		Kind:  kind,
+		Pos:   pos,
+		Angle: angle,
+	})
+}
+
 type AddShapeData struct {
 	Kind  assets.Kind
 	Pos   cp.Vector
@@ -0,0 +1,73 @@
+package physics
+
+import (
+	"github.com/jakecoffman/cp/v2"
+	assets "github.com/ponyo877/suika-shaker/assets/image"
+)
+
+const (
+	// SpawnCandidates is how many random places FindSpawn tries after the
+	// preferred one.
+	SpawnCandidates = 16
+	// spawnScanStep spaces the grid FindSpawn sweeps before declaring the
+	// board full.
+	spawnScanStep = 20
+	// SpawnOverlap is how far a new fruit may sink into the fruits around
+	// it; the solver eases overlaps this small apart without launching
+	// anything.
+	SpawnOverlap = 12
+)
+
+// Fits reports whether a fruit of kind at pos, turned by angle, would clear
+// the walls and sink no more than SpawnOverlap into any other fruit. Unlike a
+// point check it tests the fruit's own outline, so a small fruit fits where a
+// large one would not.
+func (m *Manager) Fits(kind assets.Kind, pos cp.Vector, angle float64) bool {
+	if !assets.Exists(kind) {
+		return false
+	}
+	vectors := assets.Get(kind).Vectors
+
+	body := cp.NewKinematicBody()
+	body.SetPosition(pos)
+	body.SetAngle(angle)
+	probe := cp.NewPolyShape(body, len(vectors), vectors, cp.NewTransformIdentity(), 0)
+	fits := true
+	m.space.ShapeQuery(probe, func(shape *cp.Shape, points *cp.ContactPointSet) {
+		if shape.Body().UserData == nil {
+			fits = false
+			return
+		}
+		for _, point := range points.Points[:points.Count] {
+			if point.Distance < -SpawnOverlap {
+				fits = false
+			}
+		}
+	})
+	return fits
+}
+
+// FindSpawn returns a place a fruit of kind fits: preferred if it is free,
+// else the first free one of SpawnCandidates places drawn from sample, else
+// the first free point of a sweep across the container. It returns false only
+// when nowhere on the board has room for the fruit.
+func (m *Manager) FindSpawn(kind assets.Kind, preferred cp.Vector, angle float64, sample func() cp.Vector) (cp.Vector, bool) {
+	if m.Fits(kind, preferred, angle) {
+		return preferred, true
+	}
+	for range SpawnCandidates {
+		if pos := sample(); m.Fits(kind, pos, angle) {
+			return pos, true
+		}
+	}
+
+	// Sweep from the top down, where a new fruit has the most room to fall.
+	for y := float64(spawnScanStep); y < BoardHeight; y += spawnScanStep {
+		for x := m.left + spawnScanStep; x < m.right; x += spawnScanStep {
+			if pos := (cp.Vector{X: x, Y: y}); m.Fits(kind, pos, angle) {
+				return pos, true
+			}
+		}
+	}
+	return cp.Vector{}, false
+}
@@ -0,0 +1,169 @@
+package replay
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/ponyo877/suika-shaker/internal/level"
+	"github.com/ponyo877/suika-shaker/internal/mode"
+	"github.com/ponyo877/suika-shaker/internal/sim"
+)
+
+// Version is bumped whenever the simulation changes in a way that makes
+// older replays play back differently: version 2 places each fruit where it
+// fits, version 3 thickens the walls and caps fruit speed, version 4 counts
+// an escaped fruit with nowhere to go as a failed drop, version 5 stops
+// levels drawing a random fruit they never use, and version 6 lets two
+// merges into the same kind in one step both place their fruit.
+const Version = 6
+
+// Segment is a run of N consecutive ticks that all received the same input.
+type Segment struct {
+	N        int  `json:"n"`
+	GravityX int  `json:"gx"`
+	GravityY int  `json:"gy"`
+	PointerX int  `json:"px,omitempty"`
+	PointerY int  `json:"py,omitempty"`
+	Pressed  bool `json:"p,omitempty"`
+}
+
+func (s Segment) input() sim.Input {
+	return sim.Input{
+		GravityX: s.GravityX,
+		GravityY: s.GravityY,
+		PointerX: s.PointerX,
+		PointerY: s.PointerY,
+		Pressed:  s.Pressed,
+	}
+}
+
+type Replay struct {
+	Version        int          `json:"version"`
+	Mode           mode.Mode    `json:"mode"`
+	Level          *level.Level `json:"level,omitempty"`
+	Seed           int64        `json:"seed"`
+	Ticks          int          `json:"ticks"`
+	Score          int          `json:"score"`
+	WatermelonHits int          `json:"watermelon_hits"`
+	Segments       []Segment    `json:"segments"`
+}
+
+// New starts recording a round of m; the rules are stored with the inputs so
+// a replay can be re-simulated without knowing how the mode was built.
+func New(m mode.Mode, seed int64) *Replay {
+	return &Replay{Version: Version, Mode: m, Seed: seed}
+}
+
+func (r *Replay) Record(in sim.Input) {
+	r.Ticks++
+	if n := len(r.Segments); n > 0 && r.Segments[n-1].input() == in {
+		r.Segments[n-1].N++
+		return
+	}
+	r.Segments = append(r.Segments, Segment{
+		N:        1,
+		GravityX: in.GravityX,
+		GravityY: in.GravityY,
+		PointerX: in.PointerX,
+		PointerY: in.PointerY,
+		Pressed:  in.Pressed,
+	})
+}
+
+func (r *Replay) Finish(score, watermelonHits int) {
+	r.Score = score
+	r.WatermelonHits = watermelonHits
+}
+
+// Player yields a replay's inputs one tick at a time.
+type Player struct {
+	replay  *Replay
+	segment int
+	offset  int
+}
+
+func (r *Replay) Player() *Player {
+	return &Player{replay: r}
+}
+
+func (p *Player) Next() (sim.Input, bool) {
+	for p.segment < len(p.replay.Segments) {
+		seg := p.replay.Segments[p.segment]
+		if p.offset < seg.N {
+			p.offset++
+			return seg.input(), true
+		}
+		p.segment++
+		p.offset = 0
+	}
+	return sim.Input{}, false
+}
+
+// Length returns how many ticks the segments cover, or false if a segment is
+// empty or they cover more than limit. It is checked before playing back a
+// replay from anyone but ourselves, whose Ticks cannot be trusted.
+func (r *Replay) Length(limit int) (int, bool) {
+	ticks := 0
+	for _, seg := range r.Segments {
+		if seg.N <= 0 || seg.N > limit-ticks {
+			return 0, false
+		}
+		ticks += seg.N
+	}
+	return ticks, true
+}
+
+// Simulate plays the replay's inputs through a fresh round and returns it, so
+// the score a replay claims can be checked against the one its inputs reach.
+// A replay longer than maxTicks is refused rather than played.
+func (r *Replay) Simulate(maxTicks int) (*sim.Simulation, error) {
+	if _, ok := r.Length(maxTicks); !ok {
+		return nil, fmt.Errorf("replay is longer than %d ticks", maxTicks)
+	}
+	s := sim.New(r.Mode, r.Seed)
+	if r.Level != nil {
+		s.Level = r.Level
+		s.Reset(r.Seed)
+	}
+	p := r.Player()
+	for in, ok := p.Next(); ok; in, ok = p.Next() {
+		s.Step(in)
+	}
+	return s, nil
+}
+
+func (r *Replay) Write(w io.Writer) error {
+	return json.NewEncoder(w).Encode(r)
+}
+
+func Read(rd io.Reader) (*Replay, error) {
+	var r Replay
+	if err := json.NewDecoder(rd).Decode(&r); err != nil {
+		return nil, err
+	}
+	if r.Version != Version {
+		return nil, errors.New("unsupported replay version")
+	}
+	return &r, nil
+}
+
+func Load(path string) (*Replay, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Read(f)
+}
+
+func (r *Replay) Save(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return r.Write(f)
+}
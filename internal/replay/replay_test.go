@@ -0,0 +1,51 @@
+package replay
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+// TestGoldenReplay plays back a recorded round of endless, which tilts,
+// shakes and sets off power-ups, and checks that it still lasts as long and
+// scores as much as when it was recorded. A failure means the simulation no
+// longer plays old replays back the same way: bump Version and record the
+// replay again.
+func TestGoldenReplay(t *testing.T) {
+	rp, err := Load(filepath.Join("testdata", "endless.replay.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := rp.Simulate(rp.Ticks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.State.Count != rp.Ticks {
+		t.Errorf("played %d ticks, recorded %d", s.State.Count, rp.Ticks)
+	}
+	if s.State.Score != rp.Score {
+		t.Errorf("scored %d, recorded %d", s.State.Score, rp.Score)
+	}
+	if s.State.WatermelonHits != rp.WatermelonHits {
+		t.Errorf("hit %d watermelons, recorded %d", s.State.WatermelonHits, rp.WatermelonHits)
+	}
+}
+
+func TestLength(t *testing.T) {
+	rp := &Replay{Segments: []Segment{{N: 3}, {N: 4}}}
+	if ticks, ok := rp.Length(7); !ok || ticks != 7 {
+		t.Errorf("Length(7) = %d, %v; want 7, true", ticks, ok)
+	}
+	if _, ok := rp.Length(6); ok {
+		t.Error("Length(6) accepted 7 ticks")
+	}
+
+	huge := &Replay{Segments: []Segment{{N: 1 << 62}, {N: 1 << 62}, {N: 1 << 62}}}
+	if _, ok := huge.Length(1 << 20); ok {
+		t.Error("Length accepted a segment longer than the limit")
+	}
+	empty := &Replay{Segments: []Segment{{N: 0}}}
+	if _, ok := empty.Length(10); ok {
+		t.Error("Length accepted an empty segment")
+	}
+}
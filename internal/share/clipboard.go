@@ -0,0 +1,52 @@
+//go:build !js || !wasm
+
+package share
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+)
+
+// CopyImageToClipboard places the PNG at path on the system clipboard using
+// the platform's own tooling, since Ebitengine has no clipboard API.
+func CopyImageToClipboard(path string) error {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("osascript", "-e",
+			fmt.Sprintf(`set the clipboard to (read (POSIX file %q) as «class PNGf»)`, abs))
+	case "windows":
+		cmd = exec.Command("powershell", "-NoProfile", "-STA", "-Command",
+			fmt.Sprintf(`Add-Type -AssemblyName System.Windows.Forms; Add-Type -AssemblyName System.Drawing; [System.Windows.Forms.Clipboard]::SetImage([System.Drawing.Image]::FromFile('%s'))`, abs))
+	default:
+		f, err := os.Open(abs)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		switch {
+		case os.Getenv("WAYLAND_DISPLAY") != "":
+			cmd = exec.Command("wl-copy", "--type", "image/png")
+		case os.Getenv("DISPLAY") != "":
+			cmd = exec.Command("xclip", "-selection", "clipboard", "-t", "image/png", "-i")
+		default:
+			return errors.New("no display available for clipboard")
+		}
+		cmd.Stdin = f
+	}
+
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%s: %v: %s", cmd.Path, err, out)
+	}
+	return nil
+}
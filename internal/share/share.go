@@ -0,0 +1,96 @@
+package share
+
+import (
+	"bytes"
+	"encoding/json"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/ponyo877/suika-shaker/internal/i18n"
+	"github.com/ponyo877/suika-shaker/internal/replay"
+)
+
+const GameURL = "https://ponyo877.github.io/suika-shaker/"
+
+type Result struct {
+	Score          int       `json:"score"`
+	WatermelonHits int       `json:"watermelon_hits"`
+	MaxCombo       int       `json:"max_combo"`
+	PlayedAt       time.Time `json:"played_at"`
+	ShareText      string    `json:"share_text"`
+	Screenshot     string    `json:"screenshot,omitempty"`
+}
+
+func NewResult(score, watermelonHits, maxCombo int, playedAt time.Time) Result {
+	return Result{
+		Score:          score,
+		WatermelonHits: watermelonHits,
+		MaxCombo:       maxCombo,
+		PlayedAt:       playedAt,
+		ShareText:      Text(score, watermelonHits),
+	}
+}
+
+func Text(score, watermelonHits int) string {
+	return i18n.Tf("share_text", score, watermelonHits, GameURL)
+}
+
+func EncodePNG(img image.Image) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// BaseName is the file name, without extension, under which everything
+// exported from a round played at playedAt is saved.
+func BaseName(playedAt time.Time) string {
+	return "suika-shaker-" + playedAt.Format("20060102-150405")
+}
+
+// ExportGIF writes an animated clip of the round next to its result files.
+func ExportGIF(dir string, gifData []byte, playedAt time.Time) (string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, BaseName(playedAt)+".gif")
+	return path, os.WriteFile(path, gifData, 0644)
+}
+
+// ExportReplay saves the round's replay next to its result files so it can
+// be re-rendered or attached to bug reports.
+func ExportReplay(dir string, r *replay.Replay, playedAt time.Time) (string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, BaseName(playedAt)+".replay.json")
+	return path, r.Save(path)
+}
+
+// Export writes the PNG screenshot and a JSON summary of result into dir and
+// returns the path of the written image.
+func Export(dir string, pngData []byte, result Result) (string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+
+	base := BaseName(result.PlayedAt)
+	imagePath := filepath.Join(dir, base+".png")
+	if err := os.WriteFile(imagePath, pngData, 0644); err != nil {
+		return "", err
+	}
+
+	result.Screenshot = filepath.Base(imagePath)
+	summary, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(filepath.Join(dir, base+".json"), summary, 0644); err != nil {
+		return "", err
+	}
+	return imagePath, nil
+}
@@ -0,0 +1,79 @@
+package sim
+
+import (
+	"maps"
+	"math/rand"
+
+	"github.com/jakecoffman/cp/v2"
+	assets "github.com/ponyo877/suika-shaker/assets/image"
+	"github.com/ponyo877/suika-shaker/internal/physics"
+)
+
+// countingSource is the simulation's random source. It remembers how many
+// values it has produced so that a clone can be brought to the same point.
+type countingSource struct {
+	seed int64
+	n    int
+	src  rand.Source
+}
+
+func newCountingSource(seed int64) *countingSource {
+	return &countingSource{seed: seed, src: rand.NewSource(seed)}
+}
+
+func (c *countingSource) Int63() int64 {
+	c.n++
+	return c.src.Int63()
+}
+
+func (c *countingSource) Seed(seed int64) {
+	c.seed, c.n = seed, 0
+	c.src.Seed(seed)
+}
+
+func (c *countingSource) clone() *countingSource {
+	d := newCountingSource(c.seed)
+	for d.n < c.n {
+		d.Int63()
+	}
+	return d
+}
+
+// Clone returns an independent copy of s, without events, for trying inputs
+// out ahead of time. Chipmunk cannot copy a space, so the clone's space is
+// rebuilt fruit by fruit with the same positions and velocities; contact
+// caches are not carried over, which makes a clone's future close to the
+// original's rather than identical.
+func (s *Simulation) Clone() *Simulation {
+	state := *s.State
+	state.HiScores = maps.Clone(s.State.HiScores)
+	state.Created = maps.Clone(s.State.Created)
+
+	c := &Simulation{
+		Mode:    s.Mode,
+		Level:   s.Level,
+		Seed:    s.Seed,
+		State:   &state,
+		Physics: physics.NewArenaManager(s.Mode.ArenaWidth),
+		source:  s.source.clone(),
+		grabber: newGrabber(),
+		queue:   s.queue,
+		idle:    s.idle,
+		garbage: s.garbage,
+	}
+	c.rng = rand.New(c.source)
+	c.registerCollisionHandlers()
+
+	space := c.Physics.GetSpace()
+	space.SetGravity(s.Physics.GetSpace().Gravity())
+	s.Physics.GetSpace().EachBody(func(body *cp.Body) {
+		kind, ok := body.UserData.(assets.Kind)
+		if !ok {
+			return
+		}
+		copied := c.Physics.AddFruit(kind, body.Position(), body.Angle())
+		copied.SetVelocityVector(body.Velocity())
+		copied.SetAngularVelocity(body.AngularVelocity())
+	})
+	return c
+}
@@ -0,0 +1,78 @@
+package sim
+
+import (
+	"math"
+
+	"github.com/jakecoffman/cp/v2"
+)
+
+const (
+	grabRadius   = 5.0
+	grabMaxForce = 50000
+)
+
+// grabber lets the pointer drag fruits around. It mirrors ebitencp's mouse
+// handling but is driven by Input so that drags are part of a replay.
+type grabber struct {
+	body    *cp.Body
+	joint   *cp.Constraint
+	pressed bool
+}
+
+func newGrabber() *grabber {
+	return &grabber{body: cp.NewKinematicBody()}
+}
+
+func (g *grabber) update(space *cp.Space, in Input) {
+	cursor := cp.Vector{X: float64(in.PointerX), Y: float64(in.PointerY)}
+
+	justPressed := in.Pressed && !g.pressed
+	if justPressed {
+		g.body.SetVelocityVector(cp.Vector{})
+		g.body.SetPosition(cursor)
+	} else {
+		next := g.body.Position().Lerp(cursor, 0.25)
+		g.body.SetVelocityVector(next.Sub(g.body.Position()).Mult(TicksPerSecond))
+		g.body.SetPosition(next)
+	}
+
+	switch {
+	case justPressed:
+		g.grab(space, cursor)
+	case !in.Pressed && g.pressed:
+		g.release(space)
+	}
+	g.pressed = in.Pressed
+}
+
+func (g *grabber) grab(space *cp.Space, cursor cp.Vector) {
+	info := space.PointQueryNearest(cursor, grabRadius, cp.ShapeFilter{
+		Group:      cp.NO_GROUP,
+		Categories: cp.ALL_CATEGORIES,
+		Mask:       cp.ALL_CATEGORIES,
+	})
+	if info.Shape == nil || info.Shape.Body().Mass() >= cp.INFINITY || info.Shape.Body().UserData == nil {
+		return
+	}
+
+	nearest := cursor
+	if info.Distance > 0 {
+		nearest = info.Point
+	}
+
+	body := info.Shape.Body()
+	g.joint = cp.NewPivotJoint2(g.body, body, cp.Vector{}, body.WorldToLocal(nearest))
+	g.joint.SetMaxForce(grabMaxForce)
+	g.joint.SetErrorBias(math.Pow(1.0-0.15, 60.0))
+	space.AddConstraint(g.joint)
+}
+
+func (g *grabber) release(space *cp.Space) {
+	if g.joint == nil {
+		return
+	}
+	if space.ContainsConstraint(g.joint) {
+		space.RemoveConstraint(g.joint)
+	}
+	g.joint = nil
+}
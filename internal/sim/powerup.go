@@ -0,0 +1,104 @@
+package sim
+
+import (
+	"github.com/jakecoffman/cp/v2"
+	assets "github.com/ponyo877/suika-shaker/assets/image"
+)
+
+const (
+	// FreezeTicks is how long a freeze holds gravity at zero.
+	FreezeTicks = 3 * TicksPerSecond
+	// bombRadius is how far from a bomb's centre a fruit's centre must be
+	// to survive the blast.
+	bombRadius = 130
+	// PowerUpFlash is how long a power-up that acts at once stays on the
+	// HUD.
+	PowerUpFlash = TicksPerSecond
+)
+
+// handlePowerUp runs when a power-up first touches anything. The power-up is
+// always the first shape of the arbiter, since its wildcard handler is the
+// one being called.
+func (s *Simulation) handlePowerUp(arb *cp.Arbiter, space *cp.Space, data interface{}) bool {
+	self, other := arb.Shapes()
+	kind := self.Body().UserData.(assets.Kind)
+	otherKind, otherIsFruit := other.Body().UserData.(assets.Kind)
+	otherIsFruit = otherIsFruit && !otherKind.Special()
+
+	// A rainbow only reacts to fruits; against walls and other power-ups it
+	// is solid.
+	if kind == assets.Rainbow && !otherIsFruit {
+		return true
+	}
+
+	// Each power-up goes off once, however many shapes it touches this step.
+	// A rainbow also uses up the fruit it merges with, so it passes over a
+	// fruit that is already merging with something else.
+	used := []*cp.Shape{self}
+	if kind == assets.Rainbow {
+		used = append(used, other)
+	}
+	if !s.Physics.ScheduleRemoveFruits(used...) {
+		return false
+	}
+
+	switch kind {
+	case assets.Bomb:
+		s.explode(space, self.Body().Position())
+	case assets.Rainbow:
+		// Merge as though the rainbow were a second fruit of the same kind.
+		s.merge(self, other, otherKind)
+	case assets.Freeze:
+		s.State.Frozen = FreezeTicks
+	case assets.Shrink:
+		s.shrinkLargest(space)
+	}
+
+	s.State.PowerUp, s.State.PowerUpAt = kind, s.State.Count
+	if s.Events.OnPowerUp != nil {
+		s.Events.OnPowerUp(kind)
+	}
+	return false
+}
+
+// explode removes every fruit centred within bombRadius of at.
+func (s *Simulation) explode(space *cp.Space, at cp.Vector) {
+	space.EachShape(func(shape *cp.Shape) {
+		if _, ok := shape.Body().UserData.(assets.Kind); !ok {
+			return
+		}
+		if shape.Body().Position().Distance(at) <= bombRadius {
+			s.Physics.ScheduleRemoveFruits(shape)
+		}
+	})
+}
+
+// shrinkLargest swaps the largest fruit on the board for one of the kind
+// below it; a grape simply disappears.
+func (s *Simulation) shrinkLargest(space *cp.Space) {
+	var largest *cp.Shape
+	var largestKind assets.Kind
+	space.EachShape(func(shape *cp.Shape) {
+		kind, ok := shape.Body().UserData.(assets.Kind)
+		if !ok || kind.Special() || kind <= largestKind {
+			return
+		}
+		largest, largestKind = shape, kind
+	})
+	if largest == nil {
+		return
+	}
+
+	// A fruit already merging away this step is left to do so.
+	if !s.Physics.ScheduleRemoveFruits(largest) || largestKind == assets.Min {
+		return
+	}
+	body := largest.Body()
+	s.Physics.ScheduleAddFruit(largestKind-1, body.Position(), body.Angle())
+}
+
+// PowerUpActive reports whether the last power-up went off within the given
+// number of ticks, for flashing it on the HUD.
+func (s *Simulation) PowerUpActive(ticks int) bool {
+	return s.State.PowerUp != 0 && s.State.Count-s.State.PowerUpAt < ticks
+}
@@ -0,0 +1,463 @@
+package sim
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/jakecoffman/cp/v2"
+	assets "github.com/ponyo877/suika-shaker/assets/image"
+	"github.com/ponyo877/suika-shaker/internal/gamestate"
+	"github.com/ponyo877/suika-shaker/internal/level"
+	"github.com/ponyo877/suika-shaker/internal/mode"
+	"github.com/ponyo877/suika-shaker/internal/physics"
+)
+
+const (
+	TicksPerSecond = 60
+	// settleTicks is how long a level waits after its last drop before
+	// declaring the objective failed.
+	settleTicks = 3 * TicksPerSecond
+	// garbageInterval spaces out garbage fruits so a large attack arrives as
+	// a shower rather than a single pile.
+	garbageInterval = 12
+	garbageY        = 60
+	// spawnStripHeight is how deep the top spawn zone reaches below its
+	// 50px margin, and spawnCandidates how many columns the away zone tries.
+	spawnStripHeight = 150
+	spawnCandidates  = 3
+	// DangerGrace is how long a fruit may stay out of bounds before the
+	// round ends, giving the player a warning and a chance to react.
+	DangerGrace = 2 * TicksPerSecond
+	// dangerFill is the share of the container the fruits must cover for
+	// the board to count as close to full.
+	dangerFill = 0.6
+)
+
+// Input is everything that can influence one simulation tick. Gravity is
+// quantised to integers so live play and replays step identically.
+type Input struct {
+	GravityX int
+	GravityY int
+	PointerX int
+	PointerY int
+	Pressed  bool
+}
+
+func GravityInput(ax, ay float64) Input {
+	if ax == 0 && ay == 0 {
+		return Input{GravityY: physics.DefaultGravityY}
+	}
+	return Input{
+		GravityX: int(math.Round(ax * 100)),
+		GravityY: int(math.Round(-ay * 100)),
+	}
+}
+
+type Events struct {
+	OnMerge    func(kind assets.Kind)
+	OnGameOver func()
+	OnDanger   func() // the board became dangerous, or another second of grace ran out
+	OnPowerUp  func(kind assets.Kind)
+}
+
+type Simulation struct {
+	Mode    mode.Mode
+	Level   *level.Level
+	Seed    int64
+	State   *gamestate.State
+	Physics *physics.Manager
+	Events  Events
+	rng     *rand.Rand
+	source  *countingSource
+	grabber *grabber
+	queue   int
+	idle    int
+	garbage int
+}
+
+func New(m mode.Mode, seed int64) *Simulation {
+	s := &Simulation{Mode: m, State: gamestate.NewState()}
+	s.Reset(seed)
+	return s
+}
+
+// Reset starts a fresh round of s.Mode from seed, keeping the high scores
+// and settings held in State.
+func (s *Simulation) Reset(seed int64) {
+	s.Seed = seed
+	s.source = newCountingSource(seed)
+	s.rng = rand.New(s.source)
+	s.Physics = physics.NewArenaManager(s.Mode.ArenaWidth)
+	s.grabber = newGrabber()
+	s.State.Reset()
+	s.State.Mode = s.Mode.Name
+	s.State.NextFruit = gamestate.NextFruit{
+		Kind:  assets.Grape,
+		X:     physics.BoardWidth / 2,
+		Y:     physics.BoardHeight - physics.ContainerHeight + 10,
+		Angle: 0,
+	}
+
+	s.registerCollisionHandlers()
+	s.placeStartingFruits()
+
+	s.queue = 0
+	s.idle = 0
+	s.garbage = 0
+	if s.Level != nil {
+		for _, b := range s.Level.Board {
+			s.Physics.AddFruit(b.Kind, cp.Vector{X: b.X, Y: b.Y}, b.Angle)
+		}
+		s.advanceQueue()
+	}
+}
+
+func (s *Simulation) registerCollisionHandlers() {
+	space := s.Physics.GetSpace()
+	assets.ForEach(func(kind assets.Kind, _ assets.ImageSet) {
+		ct := cp.CollisionType(kind)
+		if kind.Special() {
+			space.NewWildcardCollisionHandler(ct).BeginFunc = s.handlePowerUp
+			return
+		}
+		space.NewCollisionHandler(ct, ct).BeginFunc = s.handleCollision
+	})
+}
+
+// advanceQueue loads the level's next queued fruit, if any are left.
+func (s *Simulation) advanceQueue() {
+	if s.queue < len(s.Level.Queue) {
+		s.State.NextFruit.Kind = s.Level.Queue[s.queue]
+	}
+}
+
+// QueueLeft returns how many fruits a level still has to drop, or -1 outside
+// of levels.
+func (s *Simulation) QueueLeft() int {
+	if s.Level == nil {
+		return -1
+	}
+	return len(s.Level.Queue) - s.queue
+}
+
+// placeStartingFruits lays out the mode's head start in rows along the floor.
+func (s *Simulation) placeStartingFruits() {
+	const perRow = 4
+	left, right := s.Physics.Bounds()
+	cell := (right - left) / perRow
+	for i := 0; i < s.Mode.StartingFruits; i++ {
+		kind := assets.Kind(s.rng.Intn(2) + int(assets.Min))
+		x := left + (float64(i%perRow)+0.5)*cell + (s.rng.Float64()-0.5)*cell/4
+		y := physics.BoardHeight - 50 - float64(i/perRow)*80
+		s.Physics.AddFruit(kind, cp.Vector{X: x, Y: y}, s.rng.Float64()*2*math.Pi)
+	}
+}
+
+func (s *Simulation) Step(in Input) {
+	s.State.IncrementCount()
+
+	if s.State.Frozen > 0 {
+		s.State.Frozen--
+		s.Physics.SetGravity(0, 0)
+	} else {
+		s.Physics.SetGravity(s.Mode.Gravity(in.GravityX, in.GravityY))
+	}
+	s.grabber.update(s.Physics.GetSpace(), in)
+	s.Physics.Step(1.0 / TicksPerSecond)
+
+	s.updateDropLogic()
+	s.dropGarbage()
+	s.State.NextFruit.Angle += 0.01
+	s.checkGameOver()
+}
+
+// AddGarbage queues n grapes to fall in from the top of the board, as sent
+// by an opponent in versus play.
+func (s *Simulation) AddGarbage(n int) {
+	s.garbage += n
+}
+
+func (s *Simulation) PendingGarbage() int {
+	return s.garbage
+}
+
+func (s *Simulation) dropGarbage() {
+	if s.garbage == 0 || s.State.ShowGameOverDialog || s.State.Count%garbageInterval != 0 {
+		return
+	}
+
+	left, right := s.Physics.Bounds()
+	x := left + float64(s.rng.Intn(int(right-left)-100)+50)
+	if !s.Physics.Fits(assets.Grape, cp.Vector{X: x, Y: garbageY}, 0) {
+		return
+	}
+	s.garbage--
+	s.Physics.ScheduleAddFruit(assets.Grape, cp.Vector{X: x, Y: garbageY}, 0)
+}
+
+// Remaining returns the ticks left in a timed round, or -1 if the mode has no
+// time limit.
+func (s *Simulation) Remaining() int {
+	return s.Mode.Remaining(s.State.Count)
+}
+
+func (s *Simulation) checkGameOver() {
+	if s.State.ShowGameOverDialog {
+		return
+	}
+
+	if s.Mode.RecoverEscapes {
+		s.recoverEscaped()
+	}
+	outOfBounds := s.Physics.CheckBodiesOutOfBounds()
+	switch {
+	case !outOfBounds:
+		s.State.OutOfBounds = 0
+	case s.Mode.NoGameOver:
+		s.Physics.ScheduleRemoveFruitsOutOfBounds()
+	default:
+		s.State.OutOfBounds++
+		if s.State.OutOfBounds >= DangerGrace {
+			s.State.TriggerGameOver()
+		}
+	}
+	s.updateDanger(outOfBounds)
+
+	if s.Mode.Remaining(s.State.Count) == 0 {
+		s.State.TriggerGameOver()
+	}
+
+	if s.Level != nil && !s.State.GameOver {
+		s.checkObjective()
+	}
+
+	if s.State.GameOver {
+		s.State.PrepareGameOverDialog()
+		s.Physics.StopAllBodies()
+		s.grabber.release(s.Physics.GetSpace())
+		if s.Events.OnGameOver != nil {
+			s.Events.OnGameOver()
+		}
+	}
+}
+
+// recoverEscaped puts fruits that got out of the container back in where
+// they fit, at rest. One that fits nowhere is taken off the board and counts
+// as a drop that found no room, so a board too full to take it back still
+// ends the round.
+func (s *Simulation) recoverEscaped() {
+	for _, body := range s.Physics.Escaped() {
+		kind := body.UserData.(assets.Kind)
+		x, y := s.spawnPosition()
+		pos, ok := s.Physics.FindSpawn(kind, cp.Vector{X: x, Y: y}, body.Angle(), func() cp.Vector {
+			x, y := s.spawnPosition()
+			return cp.Vector{X: x, Y: y}
+		})
+		if ok {
+			s.Physics.MoveFruit(body, pos)
+		} else {
+			s.Physics.RemoveBody(body)
+			s.spawnFailed()
+		}
+	}
+}
+
+// updateDanger raises the warning while a fruit is out of bounds, a drop
+// found no room, or the fruits cover most of the container.
+func (s *Simulation) updateDanger(outOfBounds bool) {
+	danger := outOfBounds && !s.Mode.NoGameOver
+	if s.State.SpawnFailCount > 0 && !s.Mode.NoGameOver {
+		danger = true
+	}
+	if !danger {
+		left, right := s.Physics.Bounds()
+		danger = s.Physics.FilledArea() >= dangerFill*(right-left)*physics.BoardHeight
+	}
+
+	// Beep when the warning comes on, then count down the grace seconds.
+	beep := danger && !s.State.Danger
+	if s.State.OutOfBounds > 0 && s.State.OutOfBounds%TicksPerSecond == 0 {
+		beep = true
+	}
+	s.State.Danger = danger
+	if beep && !s.State.GameOver && s.Events.OnDanger != nil {
+		s.Events.OnDanger()
+	}
+}
+
+// DangerCountdown returns the whole seconds left before an out-of-bounds
+// fruit ends the round, or 0 if none is out.
+func (s *Simulation) DangerCountdown() int {
+	if s.State.OutOfBounds == 0 {
+		return 0
+	}
+	return (DangerGrace - s.State.OutOfBounds + TicksPerSecond - 1) / TicksPerSecond
+}
+
+func (s *Simulation) checkObjective() {
+	if s.Level.Objective.Met(s.State, s.Physics.CountFruits) {
+		s.State.LevelCleared = true
+		s.State.Stars = s.Level.StarsFor(s.State.Drops)
+		s.State.TriggerGameOver()
+		return
+	}
+
+	if s.QueueLeft() == 0 {
+		s.idle++
+		if s.idle >= settleTicks {
+			s.State.TriggerGameOver()
+		}
+	}
+}
+
+func (s *Simulation) updateDropLogic() {
+	if s.State.ShowGameOverDialog || s.QueueLeft() == 0 {
+		return
+	}
+
+	s.State.IncrementDropCount()
+
+	if s.State.DropCount >= s.Mode.DropIntervalAt(s.State.Count) {
+		s.dropFruit()
+		s.State.ResetDropCount()
+	}
+}
+
+// spawnFailed records a fruit that found no room on the board. Fruits may
+// still merge and make some, so only a board that stays full ends the round.
+func (s *Simulation) spawnFailed() {
+	s.State.SpawnFailCount++
+	if s.State.SpawnFailCount >= physics.MaxSpawnFailures && !s.Mode.NoGameOver {
+		s.State.TriggerGameOver()
+	}
+}
+
+func (s *Simulation) dropFruit() {
+	next := &s.State.NextFruit
+	pos, ok := s.Physics.FindSpawn(next.Kind, cp.Vector{X: next.X, Y: next.Y}, next.Angle, func() cp.Vector {
+		x, y := s.spawnPosition()
+		return cp.Vector{X: x, Y: y}
+	})
+	if !ok {
+		s.spawnFailed()
+		return
+	}
+
+	s.State.SpawnFailCount = 0
+
+	s.Physics.ScheduleAddFruit(next.Kind, pos, next.Angle)
+
+	s.State.Drops++
+	if s.Level != nil {
+		s.queue++
+		s.advanceQueue()
+	} else {
+		next.Kind = s.spawnKind()
+	}
+	next.X, next.Y = s.spawnPosition()
+	next.Angle = s.rng.Float64() * 2 * math.Pi
+}
+
+// spawnPosition picks where the next fruit appears under the mode's spawn
+// zone.
+func (s *Simulation) spawnPosition() (float64, float64) {
+	left, right := s.Physics.Bounds()
+	randomX := func() float64 {
+		return left + float64(s.rng.Intn(int(right-left)-100)+50)
+	}
+
+	switch s.Mode.SpawnZone {
+	case mode.SpawnTop:
+		return randomX(), float64(s.rng.Intn(spawnStripHeight) + 50)
+	case mode.SpawnAway:
+		y := float64(s.rng.Intn(spawnStripHeight) + 50)
+		centroid, ok := s.Physics.Centroid()
+		if !ok {
+			return randomX(), y
+		}
+		// Of a few random columns, take the one farthest from the pile so
+		// that drops spread the load instead of stacking on it.
+		best := randomX()
+		for range spawnCandidates - 1 {
+			if x := randomX(); math.Abs(x-centroid.X) > math.Abs(best-centroid.X) {
+				best = x
+			}
+		}
+		return best, y
+	}
+	return randomX(), float64(s.rng.Intn(physics.BoardHeight-100) + 50)
+}
+
+// spawnKind picks the kind of the next fruit to drop from the spawn weights
+// in force at this point of the round.
+func (s *Simulation) spawnKind() assets.Kind {
+	if s.Mode.PowerUps > 0 && s.rng.Intn(100) < s.Mode.PowerUps {
+		return assets.SpecialMin + assets.Kind(s.rng.Intn(int(assets.SpecialMax-assets.SpecialMin)+1))
+	}
+	weights := s.Mode.SpawnWeightsAt(s.State.Count, s.State.Score)
+	if len(weights) == 0 {
+		return assets.Kind(s.rng.Intn(2) + int(assets.Min))
+	}
+	weights = weights[:min(len(weights), int(assets.Max-assets.Min)+1)]
+
+	total := 0
+	for _, w := range weights {
+		total += max(0, w)
+	}
+	if total == 0 {
+		return assets.Min
+	}
+	r := s.rng.Intn(total)
+	for i, w := range weights {
+		if r < max(0, w) {
+			return assets.Min + assets.Kind(i)
+		}
+		r -= max(0, w)
+	}
+	return assets.Min
+}
+
+func (s *Simulation) handleCollision(arb *cp.Arbiter, space *cp.Space, data interface{}) bool {
+	shape1, shape2 := arb.Shapes()
+
+	kind1, ok1 := shape1.Body().UserData.(assets.Kind)
+	kind2, ok2 := shape2.Body().UserData.(assets.Kind)
+	if !ok1 || !ok2 {
+		return false
+	}
+
+	// A fruit touching two of its kind at once merges with only one of them.
+	if !s.Physics.ScheduleRemoveFruits(shape1, shape2) {
+		return false
+	}
+
+	if kind1 == assets.Watermelon && kind2 == assets.Watermelon {
+		s.State.IncrementWatermelonHits()
+	}
+
+	s.merge(shape1, shape2, kind1)
+	return false
+}
+
+// merge scores kind for two touching shapes already scheduled for removal
+// and puts one fruit the size up from kind between them, or nothing if kind
+// is the largest.
+func (s *Simulation) merge(shape1, shape2 *cp.Shape, kind assets.Kind) {
+	s.State.AddScore(kind.Score())
+	s.State.RegisterMerge()
+
+	if s.Events.OnMerge != nil {
+		s.Events.OnMerge(kind)
+	}
+
+	hasNext, nextKind := kind.Next()
+	if !hasNext {
+		return
+	}
+	s.State.RegisterCreated(nextKind)
+
+	pos := shape1.Body().Position().Clone()
+	pos.Sub(shape2.Body().Position()).Mult(0.5).Add(shape2.Body().Position())
+	angle := (shape1.Body().Angle() + shape2.Body().Angle()) / 2
+	s.Physics.ScheduleAddFruit(nextKind, pos, angle)
+}
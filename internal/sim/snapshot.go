@@ -0,0 +1,35 @@
+package sim
+
+import (
+	"math"
+
+	"github.com/jakecoffman/cp/v2"
+	assets "github.com/ponyo877/suika-shaker/assets/image"
+	"github.com/ponyo877/suika-shaker/internal/physics"
+	"github.com/ponyo877/suika-shaker/internal/snapshot"
+)
+
+// Snapshot captures the board for sending to opponents or spectators.
+func (s *Simulation) Snapshot() snapshot.Snapshot {
+	snap := snapshot.Snapshot{
+		Tick:  s.State.Count,
+		Score: s.State.Score,
+		Over:  s.State.GameOver,
+	}
+	if left, right := s.Physics.Bounds(); left > 0 || right < physics.BoardWidth {
+		snap.Left, snap.Right = int(left), int(right)
+	}
+
+	s.Physics.GetSpace().EachShape(func(shape *cp.Shape) {
+		if polyShape, ok := shape.Class.(*cp.PolyShape); ok {
+			vec := polyShape.Body().Position()
+			snap.Bodies = append(snap.Bodies, snapshot.Body{
+				Kind:  int(polyShape.Body().UserData.(assets.Kind)),
+				X:     int(math.Round(vec.X)),
+				Y:     int(math.Round(vec.Y - physics.PaddingBottom)),
+				Angle: polyShape.Body().Angle(),
+			})
+		}
+	})
+	return snap
+}
@@ -0,0 +1,251 @@
+package skin
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+
+	_ "golang.org/x/image/webp"
+
+	assets "github.com/ponyo877/suika-shaker/assets/image"
+	"github.com/ponyo877/suika-shaker/assets/sound"
+	"github.com/ponyo877/suika-shaker/internal/ui"
+)
+
+const ManifestFile = "skin.json"
+
+type Manifest struct {
+	Name    string                `json:"name"`
+	Fruits  map[string]FruitEntry `json:"fruits"`
+	Icons   map[string]string     `json:"icons"`
+	Sounds  map[string]string     `json:"sounds"`
+	Palette map[string]string     `json:"palette"`
+}
+
+type FruitEntry struct {
+	Image string  `json:"image"`
+	Scale float64 `json:"scale"`
+}
+
+type Skin struct {
+	Name    string
+	Palette *ui.ColorPalette
+
+	fruits map[assets.Kind]fruit
+	icons  map[assets.IconKind]image.Image
+	sounds map[sound.Kind][]byte
+}
+
+type fruit struct {
+	img   image.Image
+	scale float64
+}
+
+var soundNames = map[string]sound.Kind{
+	"background": sound.Background,
+	"gameover":   sound.GameOver,
+	"join":       sound.Join,
+	"suikajoin":  sound.SuikaJoin,
+}
+
+type readFunc func(name string) ([]byte, error)
+
+func LoadPath(p string) (*Skin, error) {
+	if strings.HasSuffix(strings.ToLower(p), ".zip") {
+		r, err := zip.OpenReader(p)
+		if err != nil {
+			return nil, err
+		}
+		defer r.Close()
+		return LoadFS(r)
+	}
+	return LoadFS(os.DirFS(p))
+}
+
+func LoadFS(fsys fs.FS) (*Skin, error) {
+	return load(func(name string) ([]byte, error) {
+		return fs.ReadFile(fsys, name)
+	})
+}
+
+func LoadURL(url string) (*Skin, error) {
+	if strings.HasSuffix(strings.ToLower(url), ".zip") {
+		data, err := fetch(url)
+		if err != nil {
+			return nil, err
+		}
+		r, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			return nil, err
+		}
+		return LoadFS(r)
+	}
+
+	base := strings.TrimSuffix(url, "/")
+	return load(func(name string) ([]byte, error) {
+		return fetch(base + "/" + name)
+	})
+}
+
+func fetch(url string) ([]byte, error) {
+	resp, err := http.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetch %s: %s", url, resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+func load(read readFunc) (*Skin, error) {
+	data, err := read(ManifestFile)
+	if err != nil {
+		return nil, fmt.Errorf("read manifest: %w", err)
+	}
+
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("parse manifest: %w", err)
+	}
+
+	s := &Skin{
+		Name:   m.Name,
+		fruits: make(map[assets.Kind]fruit),
+		icons:  make(map[assets.IconKind]image.Image),
+		sounds: make(map[sound.Kind][]byte),
+	}
+
+	for name, entry := range m.Fruits {
+		kind, ok := assets.ParseKind(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown fruit %q", name)
+		}
+		img, err := readImage(read, entry.Image)
+		if err != nil {
+			return nil, err
+		}
+		f := fruit{img: img, scale: entry.Scale}
+		if f.scale == 0 {
+			f.scale = assets.Get(kind).Scale
+		}
+		s.fruits[kind] = f
+	}
+
+	for name, file := range m.Icons {
+		kind, ok := assets.ParseIconKind(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown icon %q", name)
+		}
+		img, err := readImage(read, file)
+		if err != nil {
+			return nil, err
+		}
+		s.icons[kind] = img
+	}
+
+	for name, file := range m.Sounds {
+		kind, ok := soundNames[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown sound %q", name)
+		}
+		data, err := read(path.Clean(file))
+		if err != nil {
+			return nil, fmt.Errorf("read sound %s: %w", file, err)
+		}
+		s.sounds[kind] = data
+	}
+
+	if len(m.Palette) > 0 {
+		palette, err := parsePalette(m.Palette)
+		if err != nil {
+			return nil, err
+		}
+		s.Palette = &palette
+	}
+
+	return s, nil
+}
+
+func readImage(read readFunc, file string) (image.Image, error) {
+	data, err := read(path.Clean(file))
+	if err != nil {
+		return nil, fmt.Errorf("read image %s: %w", file, err)
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("decode image %s: %w", file, err)
+	}
+	return img, nil
+}
+
+func parsePalette(entries map[string]string) (ui.ColorPalette, error) {
+	palette := ui.NewColorPalette()
+	fields := map[string]*color.NRGBA{
+		"beige":      &palette.Beige,
+		"darkteal":   &palette.DarkTeal,
+		"redbrown":   &palette.RedBrown,
+		"white":      &palette.White,
+		"black":      &palette.Black,
+		"lightgreen": &palette.LightGreen,
+		"cyan":       &palette.Cyan,
+		"overlay":    &palette.Overlay,
+	}
+
+	for name, hex := range entries {
+		field, ok := fields[strings.ToLower(name)]
+		if !ok {
+			return palette, fmt.Errorf("unknown palette colour %q", name)
+		}
+		c, err := parseHexColor(hex)
+		if err != nil {
+			return palette, fmt.Errorf("palette colour %s: %w", name, err)
+		}
+		*field = c
+	}
+	return palette, nil
+}
+
+func parseHexColor(s string) (color.NRGBA, error) {
+	c := color.NRGBA{A: 255}
+	s = strings.TrimPrefix(s, "#")
+
+	var err error
+	switch len(s) {
+	case 6:
+		_, err = fmt.Sscanf(s, "%02x%02x%02x", &c.R, &c.G, &c.B)
+	case 8:
+		_, err = fmt.Sscanf(s, "%02x%02x%02x%02x", &c.R, &c.G, &c.B, &c.A)
+	default:
+		err = fmt.Errorf("invalid colour %q", s)
+	}
+	return c, err
+}
+
+func (s *Skin) Apply() error {
+	for kind, f := range s.fruits {
+		assets.SetImage(kind, f.img, f.scale)
+	}
+	for kind, img := range s.icons {
+		assets.SetIcon(kind, img)
+	}
+	for kind, data := range s.sounds {
+		if err := sound.Replace(kind, data); err != nil {
+			return fmt.Errorf("sound %d: %w", kind, err)
+		}
+	}
+	return nil
+}
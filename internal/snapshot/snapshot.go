@@ -0,0 +1,41 @@
+// Package snapshot is a compact, renderer-independent picture of a board that
+// is cheap to send over the network many times a second.
+package snapshot
+
+import (
+	"encoding/json"
+	"math"
+)
+
+// Body is one fruit on the board. It is encoded as the JSON array
+// [kind, x, y, angle] with the position in whole screen pixels and the angle
+// in milliradians.
+type Body struct {
+	Kind  int
+	X, Y  int
+	Angle float64
+}
+
+func (b Body) MarshalJSON() ([]byte, error) {
+	return json.Marshal([4]int{b.Kind, b.X, b.Y, int(math.Round(b.Angle * 1000))})
+}
+
+func (b *Body) UnmarshalJSON(data []byte) error {
+	var a [4]int
+	if err := json.Unmarshal(data, &a); err != nil {
+		return err
+	}
+	*b = Body{Kind: a[0], X: a[1], Y: a[2], Angle: float64(a[3]) / 1000}
+	return nil
+}
+
+// Snapshot is a board at one tick. Left and Right are the arena walls and are
+// both zero when the arena spans the whole screen.
+type Snapshot struct {
+	Tick   int    `json:"tick"`
+	Score  int    `json:"score"`
+	Over   bool   `json:"over,omitempty"`
+	Left   int    `json:"left,omitempty"`
+	Right  int    `json:"right,omitempty"`
+	Bodies []Body `json:"bodies"`
+}
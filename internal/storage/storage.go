@@ -0,0 +1,46 @@
+//go:build !js || !wasm
+
+package storage
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+const appDir = "suika-shaker"
+
+// Load returns the value saved under key in the user's config directory.
+func Load(key string) (string, bool) {
+	path, err := keyPath(key)
+	if err != nil {
+		return "", false
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", false
+	}
+	return string(data), true
+}
+
+func Save(key, value string) error {
+	path, err := keyPath(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(value), 0644)
+}
+
+func keyPath(key string) (string, error) {
+	if key == "" || filepath.Base(key) != key {
+		return "", errors.New("invalid storage key")
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, appDir, key), nil
+}
@@ -0,0 +1,38 @@
+//go:build js && wasm
+
+package storage
+
+import (
+	"errors"
+	"syscall/js"
+)
+
+const keyPrefix = "suika-shaker:"
+
+// Load returns the value saved under key in the browser's localStorage.
+func Load(key string) (string, bool) {
+	ls := js.Global().Get("localStorage")
+	if !ls.Truthy() {
+		return "", false
+	}
+	v := ls.Call("getItem", keyPrefix+key)
+	if v.Type() != js.TypeString {
+		return "", false
+	}
+	return v.String(), true
+}
+
+func Save(key, value string) (err error) {
+	ls := js.Global().Get("localStorage")
+	if !ls.Truthy() {
+		return errors.New("localStorage is unavailable")
+	}
+	// setItem throws when storage is full or disabled.
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.New("localStorage is not writable")
+		}
+	}()
+	ls.Call("setItem", keyPrefix+key, value)
+	return nil
+}
@@ -0,0 +1,79 @@
+package ui
+
+import (
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// AchievementsButtonConfig opens the achievements list from the title screen.
+var AchievementsButtonConfig = ButtonConfig{X: 20, Y: 725, Width: 210, Height: 50}
+
+// AchievementRow is one line of the achievements list.
+type AchievementRow struct {
+	Label    string
+	Detail   string
+	Unlocked bool
+}
+
+// DrawAchievementsButton draws a title screen button leading to the
+// achievements list.
+func (r *Renderer) DrawAchievementsButton(screen *ebiten.Image, label string) {
+	r.drawTextButton(screen, AchievementsButtonConfig, label)
+}
+
+func (r *Renderer) drawTextButton(screen *ebiten.Image, cfg ButtonConfig, label string) {
+	colors := r.theme.Colors
+	r.drawRoundedRect(screen, cfg.X, cfg.Y, cfg.Width, cfg.Height, 15, colors.Beige)
+	r.strokePath(screen, r.createRoundedRectPath(cfg.X, cfg.Y, cfg.Width, cfg.Height, 15), colors.DarkTeal, 4)
+	r.theme.Fonts.DrawTextCentered(screen, label, 20, float64(cfg.X+cfg.Width/2), float64(cfg.Y+cfg.Height/2), colors.DarkTeal, true)
+}
+
+// DrawAchievements lists every achievement, dimming the ones still locked.
+func (r *Renderer) DrawAchievements(screen *ebiten.Image, title, subtitle string, rows []AchievementRow, paddingBottom float64) {
+	const (
+		x      = 30
+		top    = 200
+		width  = ScreenWidth - 2*x
+		height = 52
+		gap    = 8
+	)
+	colors := r.theme.Colors
+	fonts := r.theme.Fonts
+
+	r.DrawBackground(screen, paddingBottom)
+	fonts.DrawTextCentered(screen, title, 36, ScreenWidth/2, 120, colors.DarkTeal, true)
+	fonts.DrawTextCentered(screen, subtitle, 18, ScreenWidth/2, 165, colors.DarkTeal, false)
+
+	for i, row := range rows {
+		y := float32(top + i*(height+gap))
+		bg, fg := colors.Beige, colors.DarkTeal
+		if !row.Unlocked {
+			bg = colors.Cyan
+		}
+		r.drawRoundedRect(screen, x, y, width, height, 12, bg)
+		fonts.DrawTextCentered(screen, row.Label, 18, ScreenWidth/2, float64(y+height*0.33), fg, true)
+		fonts.DrawTextCentered(screen, row.Detail, 13, ScreenWidth/2, float64(y+height*0.72), fg, false)
+	}
+}
+
+// DrawToast shows a banner at the top of the screen, faded by alpha in
+// [0, 1].
+func (r *Renderer) DrawToast(screen *ebiten.Image, title, text string, alpha float64) {
+	const (
+		x      = 40
+		y      = 80
+		width  = ScreenWidth - 2*x
+		height = 64
+	)
+	colors := r.theme.Colors
+	fade := func(c color.NRGBA) color.NRGBA {
+		c.A = uint8(float64(c.A) * alpha)
+		return c
+	}
+
+	r.drawRoundedRect(screen, x, y, width, height, 14, fade(colors.Beige))
+	r.strokePath(screen, r.createRoundedRectPath(x, y, width, height, 14), fade(colors.RedBrown), 3)
+	r.theme.Fonts.DrawTextCentered(screen, title, 14, ScreenWidth/2, y+20, fade(colors.RedBrown), true)
+	r.theme.Fonts.DrawTextCentered(screen, text, 20, ScreenWidth/2, y+44, fade(colors.DarkTeal), true)
+}
@@ -0,0 +1,53 @@
+package ui
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/jakecoffman/cp/v2"
+	assets "github.com/ponyo877/suika-shaker/assets/image"
+	"github.com/ponyo877/suika-shaker/internal/physics"
+	"github.com/ponyo877/suika-shaker/internal/sim"
+)
+
+// DrawBoard draws the container and every fruit of s; it is shared by the
+// game and offline renderers so that both produce identical frames.
+func (r *Renderer) DrawBoard(screen *ebiten.Image, s *sim.Simulation) {
+	r.DrawBackground(screen, physics.PaddingBottom)
+	if left, right := s.Physics.Bounds(); left > 0 || right < ScreenWidth {
+		r.DrawArenaWalls(screen, left, right)
+	}
+
+	s.Physics.GetSpace().EachShape(func(shape *cp.Shape) {
+		if polyShape, ok := shape.Class.(*cp.PolyShape); ok {
+			vec := polyShape.Body().Position()
+			kind := polyShape.Body().UserData.(assets.Kind)
+			r.DrawFruit(screen, kind, vec.X, vec.Y-physics.PaddingBottom, polyShape.Body().Angle())
+		}
+	})
+
+	if s.State.Danger && !s.State.ShowGameOverDialog {
+		r.DrawDanger(screen, s.State.Count, s.DangerCountdown())
+	}
+	if badges := powerUpBadges(s); len(badges) > 0 {
+		r.DrawPowerUps(screen, badges)
+	}
+}
+
+// DrawHUD draws a few lines of small status text with their top left corner
+// at (x, y), in the theme's fonts so that translated text renders.
+func (r *Renderer) DrawHUD(screen *ebiten.Image, str string, x, y int) {
+	r.theme.Fonts.DrawText(screen, str, 14, float64(x), float64(y), r.theme.Colors.DarkTeal, false)
+}
+
+// powerUpBadges lists a running freeze, and briefly whichever power-up went
+// off last.
+func powerUpBadges(s *sim.Simulation) []PowerUpBadge {
+	var badges []PowerUpBadge
+	if s.State.Frozen > 0 {
+		seconds := (s.State.Frozen + sim.TicksPerSecond - 1) / sim.TicksPerSecond
+		badges = append(badges, PowerUpBadge{Kind: assets.Freeze, Seconds: seconds})
+	}
+	if s.PowerUpActive(sim.PowerUpFlash) && s.State.PowerUp != assets.Freeze {
+		badges = append(badges, PowerUpBadge{Kind: s.State.PowerUp})
+	}
+	return badges
+}
@@ -0,0 +1,27 @@
+package ui
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// dangerPulse is the period, in ticks, of the danger overlay's pulse.
+const dangerPulse = 40
+
+// DrawDanger rims the board in a pulsing warning colour, with the seconds
+// left in large type when countdown is positive.
+func (r *Renderer) DrawDanger(screen *ebiten.Image, tick, countdown int) {
+	colors := r.theme.Colors
+	pulse := 0.5 + 0.5*math.Sin(2*math.Pi*float64(tick)/dangerPulse)
+
+	rim := colors.RedBrown
+	rim.A = uint8(90 + 120*pulse)
+	width := r.theme.BoardBorderWidth * float32(2+pulse)
+	r.strokePath(screen, r.createRoundedRectPath(0, 0, ScreenWidth, ScreenHeight, 0), rim, width)
+
+	if countdown > 0 {
+		r.DrawCountdown(screen, fmt.Sprintf("%d", countdown))
+	}
+}
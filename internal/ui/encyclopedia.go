@@ -0,0 +1,109 @@
+package ui
+
+import (
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	assets "github.com/ponyo877/suika-shaker/assets/image"
+)
+
+// EncyclopediaButtonConfig opens the fruit list from the title screen.
+var EncyclopediaButtonConfig = ButtonConfig{X: 250, Y: 725, Width: 210, Height: 50}
+
+// FruitEntry is one step of the merge chain on the encyclopedia screen.
+type FruitEntry struct {
+	Kind       assets.Kind
+	Name       string
+	Points     string
+	Discovered bool
+}
+
+// DrawEncyclopediaButton draws a title screen button leading to the fruit
+// list.
+func (r *Renderer) DrawEncyclopediaButton(screen *ebiten.Image, label string) {
+	r.drawTextButton(screen, EncyclopediaButtonConfig, label)
+}
+
+// DrawEncyclopedia lists the merge chain from smallest to largest, with an
+// arrow from each fruit to the one it merges into. Fruits not yet discovered
+// are shown as silhouettes.
+func (r *Renderer) DrawEncyclopedia(screen *ebiten.Image, title, subtitle string, entries []FruitEntry, paddingBottom float64) {
+	const (
+		x      = 40
+		top    = 195
+		width  = ScreenWidth - 2*x
+		height = 50
+		gap    = 14
+		icon   = 44
+	)
+	colors := r.theme.Colors
+	fonts := r.theme.Fonts
+
+	r.DrawBackground(screen, paddingBottom)
+	fonts.DrawTextCentered(screen, title, 36, ScreenWidth/2, 120, colors.DarkTeal, true)
+	fonts.DrawTextCentered(screen, subtitle, 18, ScreenWidth/2, 165, colors.DarkTeal, false)
+
+	for i, e := range entries {
+		y := float32(top + i*(height+gap))
+		bg := colors.Beige
+		if !e.Discovered {
+			bg = colors.Cyan
+		}
+		r.drawRoundedRect(screen, x, y, width, height, 12, bg)
+		r.drawFruitIcon(screen, e.Kind, x+10+icon/2, float64(y)+height/2, icon, e.Discovered, 1)
+		fonts.DrawTextCentered(screen, e.Name, 20, ScreenWidth/2, float64(y+height/2), colors.DarkTeal, true)
+		fonts.DrawTextCentered(screen, e.Points, 16, x+width-50, float64(y+height/2), colors.RedBrown, true)
+
+		if i < len(entries)-1 {
+			// A small arrow down to the next fruit in the chain.
+			var path vector.Path
+			ay := y + height + 3
+			path.MoveTo(ScreenWidth/2-7, ay)
+			path.LineTo(ScreenWidth/2+7, ay)
+			path.LineTo(ScreenWidth/2, ay+gap-6)
+			path.Close()
+			r.fillPath(screen, path, colors.DarkTeal)
+		}
+	}
+}
+
+// DrawEvolutionRing draws the merge chain as a compact ring of fruits growing
+// clockwise from the top, centred on (cx, cy).
+func (r *Renderer) DrawEvolutionRing(screen *ebiten.Image, kinds []assets.Kind, discovered func(assets.Kind) bool, cx, cy, radius, alpha float64) {
+	const minIcon, maxIcon = 16.0, 30.0
+	colors := r.theme.Colors
+	bg := colors.Beige
+	bg.A = uint8(float64(bg.A) * alpha * 0.6)
+	vector.FillCircle(screen, float32(cx), float32(cy), float32(radius+maxIcon/2+4), bg, true)
+
+	for i, kind := range kinds {
+		angle := 2*math.Pi*float64(i)/float64(len(kinds)) - math.Pi/2
+		size := minIcon
+		if len(kinds) > 1 {
+			size += (maxIcon - minIcon) * float64(i) / float64(len(kinds)-1)
+		}
+		x := cx + radius*math.Cos(angle)
+		y := cy + radius*math.Sin(angle)
+		r.drawFruitIcon(screen, kind, x, y, size, discovered(kind), alpha)
+	}
+}
+
+// drawFruitIcon draws a fruit's image scaled to fit size pixels, centred on
+// (x, y), or a dark silhouette of it when it is not yet discovered.
+func (r *Renderer) drawFruitIcon(screen *ebiten.Image, kind assets.Kind, x, y, size float64, discovered bool, alpha float64) {
+	img := fruitTexture(kind)
+	bounds := img.Bounds()
+	scale := size / float64(max(bounds.Dx(), bounds.Dy()))
+
+	op := &ebiten.DrawImageOptions{}
+	op.Filter = ebiten.FilterLinear
+	op.GeoM.Translate(-float64(bounds.Dx())/2, -float64(bounds.Dy())/2)
+	op.GeoM.Scale(scale, scale)
+	op.GeoM.Translate(x, y)
+	if !discovered {
+		op.ColorScale.Scale(0, 0, 0, 0.5)
+	}
+	op.ColorScale.ScaleAlpha(float32(alpha))
+	screen.DrawImage(img, op)
+}
@@ -0,0 +1,30 @@
+package ui
+
+import (
+	"image"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	assets "github.com/ponyo877/suika-shaker/assets/image"
+)
+
+// textures holds the GPU copy of every catalogue image drawn so far. The
+// catalogue itself keeps plain images so that the simulation builds without
+// any graphics; a skin swapping an image in simply gets a new entry.
+var textures = map[image.Image]*ebiten.Image{}
+
+func texture(img image.Image) *ebiten.Image {
+	tex, ok := textures[img]
+	if !ok {
+		tex = ebiten.NewImageFromImage(img)
+		textures[img] = tex
+	}
+	return tex
+}
+
+func fruitTexture(kind assets.Kind) *ebiten.Image {
+	return texture(assets.Get(kind).Image)
+}
+
+func iconTexture(kind assets.IconKind) *ebiten.Image {
+	return texture(assets.GetIcon(kind))
+}
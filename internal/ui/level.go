@@ -0,0 +1,66 @@
+package ui
+
+import "github.com/hajimehoshi/ebiten/v2"
+
+// LevelButton is one tile of the level select grid.
+type LevelButton struct {
+	Label  string
+	Stars  int
+	Locked bool
+}
+
+func LevelButtonConfig(i int) ButtonConfig {
+	const (
+		size    = 120
+		gap     = 20
+		columns = 3
+		top     = 220
+		left    = (ScreenWidth - columns*size - (columns-1)*gap) / 2
+	)
+	return ButtonConfig{
+		X:      float32(left + (i%columns)*(size+gap)),
+		Y:      float32(top + (i/columns)*(size+gap)),
+		Width:  size,
+		Height: size,
+	}
+}
+
+func (r *Renderer) DrawLevelSelect(screen *ebiten.Image, title, subtitle string, buttons []LevelButton, paddingBottom float64) {
+	colors := r.theme.Colors
+	fonts := r.theme.Fonts
+
+	r.DrawBackground(screen, paddingBottom)
+	fonts.DrawTextCentered(screen, title, 36, ScreenWidth/2, 120, colors.DarkTeal, true)
+	fonts.DrawTextCentered(screen, subtitle, 18, ScreenWidth/2, 165, colors.DarkTeal, false)
+
+	for i, b := range buttons {
+		cfg := LevelButtonConfig(i)
+		bg, fg := colors.Beige, colors.DarkTeal
+		if b.Locked {
+			bg = colors.Cyan
+		}
+		r.drawRoundedRect(screen, cfg.X, cfg.Y, cfg.Width, cfg.Height, 18, bg)
+		r.strokePath(screen, r.createRoundedRectPath(cfg.X, cfg.Y, cfg.Width, cfg.Height, 18), colors.DarkTeal, 4)
+
+		centerX := cfg.X + cfg.Width/2
+		fonts.DrawTextCentered(screen, b.Label, 36, float64(centerX), float64(cfg.Y+cfg.Height*0.4), fg, true)
+		if !b.Locked {
+			r.drawStars(screen, centerX, cfg.Y+cfg.Height*0.78, 11, b.Stars)
+		}
+	}
+}
+
+// DrawLevelBanner shows the level's objective and progress under the HUD.
+func (r *Renderer) DrawLevelBanner(screen *ebiten.Image, objective, progress string) {
+	colors := r.theme.Colors
+	const (
+		x      = 20
+		y      = 70
+		width  = ScreenWidth - 2*x
+		height = 56
+	)
+	r.drawRoundedRect(screen, x, y, width, height, 14, colors.Beige)
+	r.strokePath(screen, r.createRoundedRectPath(x, y, width, height, 14), colors.DarkTeal, 3)
+	r.theme.Fonts.DrawTextCentered(screen, objective, 18, ScreenWidth/2, y+19, colors.DarkTeal, true)
+	r.theme.Fonts.DrawTextCentered(screen, progress, 14, ScreenWidth/2, y+41, colors.DarkTeal, false)
+}
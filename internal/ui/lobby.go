@@ -0,0 +1,21 @@
+package ui
+
+import "github.com/hajimehoshi/ebiten/v2"
+
+// DrawLobby lists the players waiting in an online room above a status line.
+func (r *Renderer) DrawLobby(screen *ebiten.Image, title string, players []string, status string, paddingBottom float64) {
+	colors := r.theme.Colors
+	fonts := r.theme.Fonts
+
+	r.DrawBackground(screen, paddingBottom)
+	fonts.DrawTextCentered(screen, title, 36, ScreenWidth/2, 120, colors.DarkTeal, true)
+	for i, name := range players {
+		fonts.DrawTextCentered(screen, name, 22, ScreenWidth/2, float64(200+i*40), colors.DarkTeal, false)
+	}
+	fonts.DrawTextCentered(screen, status, 18, ScreenWidth/2, 600, colors.RedBrown, true)
+}
+
+// DrawCountdown shows the seconds left before a round starts over the board.
+func (r *Renderer) DrawCountdown(screen *ebiten.Image, text string) {
+	r.theme.Fonts.DrawTextCentered(screen, text, 96, ScreenWidth/2, ScreenHeight/2, r.theme.Colors.RedBrown, true)
+}
@@ -0,0 +1,40 @@
+package ui
+
+import (
+	"fmt"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	assets "github.com/ponyo877/suika-shaker/assets/image"
+)
+
+// PowerUpBadge is a power-up shown under the buttons while it is in effect,
+// with the seconds it has left, or 0 for one that acts at once.
+type PowerUpBadge struct {
+	Kind    assets.Kind
+	Seconds int
+}
+
+// DrawPowerUps lines the badges up under the speaker button, right to left.
+func (r *Renderer) DrawPowerUps(screen *ebiten.Image, badges []PowerUpBadge) {
+	const (
+		size = 40
+		gap  = 10
+		top  = 70
+	)
+	colors := r.theme.Colors
+	for i, b := range badges {
+		x := float64(SpeakerButtonConfig.X+SpeakerButtonConfig.Width) - float64(i+1)*(size+gap) + gap
+		img := fruitTexture(b.Kind)
+		scale := size / float64(img.Bounds().Dx())
+
+		op := &ebiten.DrawImageOptions{}
+		op.Filter = ebiten.FilterLinear
+		op.GeoM.Scale(scale, scale)
+		op.GeoM.Translate(x, top)
+		screen.DrawImage(img, op)
+
+		if b.Seconds > 0 {
+			r.theme.Fonts.DrawTextCentered(screen, fmt.Sprintf("%d", b.Seconds), 16, x+size/2, top+size+12, colors.DarkTeal, true)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package ui
+
+import (
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+const maxStars = 3
+
+func (c DialogConfig) NextButton() ButtonConfig {
+	return ButtonConfig{X: c.GIFX, Y: c.ButtonY, Width: c.XButtonX + c.XButtonSize - c.GIFX, Height: c.RetryHeight}
+}
+
+// DrawResultDialog replaces the game over dialog where a round ends with a
+// verdict rather than a score, as in levels and versus play. A negative stars
+// hides the star row.
+func (r *Renderer) DrawResultDialog(screen *ebiten.Image, title, detail string, stars int, retryLabel, nextLabel string) {
+	cfg := r.theme.Dialog
+	colors := r.theme.Colors
+	fonts := r.theme.Fonts
+
+	var overlayPath vector.Path
+	overlayPath.MoveTo(0, 0)
+	overlayPath.LineTo(ScreenWidth, 0)
+	overlayPath.LineTo(ScreenWidth, ScreenHeight)
+	overlayPath.LineTo(0, ScreenHeight)
+	overlayPath.Close()
+	r.fillPath(screen, overlayPath, colors.Overlay)
+
+	r.drawRoundedRect(screen, cfg.X, cfg.Y, cfg.Width, cfg.Height, cfg.Radius, colors.Beige)
+	r.strokePath(screen, r.createRoundedRectPath(cfg.X, cfg.Y, cfg.Width, cfg.Height, cfg.Radius), colors.DarkTeal, cfg.BorderWidth)
+
+	centerX := cfg.X + cfg.Width/2
+	fonts.DrawTextCentered(screen, title, 40, float64(centerX), float64(cfg.Y+70), colors.RedBrown, true)
+	if stars >= 0 {
+		r.drawStars(screen, centerX, cfg.Y+165, 32, stars)
+	}
+	fonts.DrawTextCentered(screen, detail, 18, float64(centerX), float64(cfg.Y+255), colors.DarkTeal, true)
+
+	r.drawRoundedRect(screen, cfg.RetryX, cfg.ButtonY, cfg.RetryWidth, cfg.RetryHeight, cfg.RetryRadius, colors.RedBrown)
+	fonts.DrawTextCentered(screen, retryLabel, 26, float64(cfg.RetryX+cfg.RetryWidth/2), float64(cfg.ButtonY+cfg.RetryHeight/2), colors.White, true)
+
+	next := cfg.NextButton()
+	r.drawRoundedRect(screen, next.X, next.Y, next.Width, next.Height, cfg.RetryRadius, colors.DarkTeal)
+	fonts.DrawTextCentered(screen, nextLabel, 26, float64(next.X+next.Width/2), float64(next.Y+next.Height/2), colors.Beige, true)
+}
+
+// drawStars draws a centred row of three stars with the first filled ones
+// solid and the rest outlined.
+func (r *Renderer) drawStars(screen *ebiten.Image, centerX, centerY, radius float32, filled int) {
+	colors := r.theme.Colors
+	spacing := radius * 2.3
+	for i := 0; i < maxStars; i++ {
+		x := centerX + float32(i-1)*spacing
+		path := starPath(x, centerY, radius)
+		if i < filled {
+			r.fillPath(screen, path, colors.RedBrown)
+		}
+		r.strokePath(screen, path, colors.DarkTeal, radius/6)
+	}
+}
+
+func starPath(cx, cy, radius float32) vector.Path {
+	var path vector.Path
+	for i := 0; i < 10; i++ {
+		rad := radius
+		if i%2 == 1 {
+			rad = radius * 0.45
+		}
+		angle := float64(i)*math.Pi/5 - math.Pi/2
+		x := cx + rad*float32(math.Cos(angle))
+		y := cy + rad*float32(math.Sin(angle))
+		if i == 0 {
+			path.MoveTo(x, y)
+		} else {
+			path.LineTo(x, y)
+		}
+	}
+	path.Close()
+	return path
+}
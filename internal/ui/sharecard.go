@@ -0,0 +1,139 @@
+package ui
+
+import (
+	"fmt"
+	"image/color"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	assets "github.com/ponyo877/suika-shaker/assets/image"
+	"github.com/ponyo877/suika-shaker/internal/i18n"
+	qrcode "github.com/skip2/go-qrcode"
+)
+
+type CardFormat struct {
+	Name   string
+	Width  int
+	Height int
+}
+
+var (
+	CardLandscape = CardFormat{Name: "landscape", Width: 1200, Height: 675}
+	CardSquare    = CardFormat{Name: "square", Width: 1080, Height: 1080}
+	CardPortrait  = CardFormat{Name: "portrait", Width: 1080, Height: 1350}
+)
+
+func LookupCardFormat(name string) (CardFormat, bool) {
+	for _, f := range []CardFormat{CardLandscape, CardSquare, CardPortrait} {
+		if f.Name == name {
+			return f, true
+		}
+	}
+	return CardFormat{}, false
+}
+
+type ShareCard struct {
+	Score          int
+	WatermelonHits int
+	MaxCombo       int
+	Seed           int64
+	Date           time.Time
+	Board          *ebiten.Image
+	URL            string
+}
+
+// RenderShareCard composes a branded result image: the final board on the
+// left and logo, stats, date and an optional QR code to URL on the right.
+func (r *Renderer) RenderShareCard(card ShareCard, format CardFormat) *ebiten.Image {
+	colors := r.theme.Colors
+	fonts := r.theme.Fonts
+	w, h := float32(format.Width), float32(format.Height)
+	pad := min32(w, h) * 0.05
+
+	img := ebiten.NewImage(format.Width, format.Height)
+	img.Fill(colors.LightGreen)
+	r.strokePath(img, r.createRoundedRectPath(0, 0, w, h, 0), colors.Cyan, pad/2)
+
+	thumbH := h - 2*pad
+	thumbW := thumbH * ScreenWidth / ScreenHeight
+	if thumbW > w/2 {
+		thumbW = w / 2
+		thumbH = thumbW * ScreenHeight / ScreenWidth
+	}
+	thumbX, thumbY := pad, (h-thumbH)/2
+	if card.Board != nil {
+		op := &ebiten.DrawImageOptions{}
+		op.Filter = ebiten.FilterLinear
+		op.GeoM.Scale(float64(thumbW)/ScreenWidth, float64(thumbH)/ScreenHeight)
+		op.GeoM.Translate(float64(thumbX), float64(thumbY))
+		img.DrawImage(card.Board, op)
+	}
+	r.strokePath(img, r.createRoundedRectPath(thumbX, thumbY, thumbW, thumbH, 0), colors.DarkTeal, pad/6)
+
+	px, py := thumbX+thumbW+pad, pad
+	pw, ph := w-px-pad, h-2*pad
+	r.drawRoundedRect(img, px, py, pw, ph, pad/2, colors.Beige)
+	r.strokePath(img, r.createRoundedRectPath(px, py, pw, ph, pad/2), colors.DarkTeal, pad/6)
+
+	unit := float64(min32(pw/400, ph/560))
+	centerX := float64(px + pw/2)
+	at := func(frac float32) float64 { return float64(py + ph*frac) }
+
+	logo := iconTexture(assets.TitleLogo)
+	logoScale := float64(pw*0.8) / float64(logo.Bounds().Dx())
+	logoScale = min(logoScale, float64(ph*0.16)/float64(logo.Bounds().Dy()))
+	logoOp := &ebiten.DrawImageOptions{}
+	logoOp.Filter = ebiten.FilterLinear
+	logoOp.GeoM.Scale(logoScale, logoScale)
+	logoOp.GeoM.Translate(centerX-float64(logo.Bounds().Dx())*logoScale/2, at(0.04))
+	img.DrawImage(logo, logoOp)
+
+	fonts.DrawTextCentered(img, i18n.T("score"), 24*unit, centerX, at(0.30), colors.DarkTeal, true)
+	fonts.DrawTextCentered(img, fmt.Sprintf("%d", card.Score), 80*unit, centerX, at(0.40), colors.RedBrown, true)
+
+	leftX, rightX := float64(px+pw/4), float64(px+pw*3/4)
+	fonts.DrawTextCentered(img, i18n.T("watermelon_hits"), 14*unit, leftX, at(0.53), colors.DarkTeal, true)
+	fonts.DrawTextCentered(img, fmt.Sprintf("%d", card.WatermelonHits), 48*unit, leftX, at(0.61), colors.DarkTeal, true)
+	fonts.DrawTextCentered(img, i18n.T("max_combo"), 14*unit, rightX, at(0.53), colors.DarkTeal, true)
+	fonts.DrawTextCentered(img, fmt.Sprintf("%d", card.MaxCombo), 48*unit, rightX, at(0.61), colors.DarkTeal, true)
+
+	footerX := centerX
+	if card.URL != "" {
+		footerX = leftX
+		r.drawQRCode(img, card.URL, px+pw*3/4, py+ph*0.84, min32(pw*0.4, ph*0.22))
+	}
+	if !card.Date.IsZero() {
+		fonts.DrawTextCentered(img, card.Date.Format("2006-01-02"), 18*unit, footerX, at(0.80), colors.DarkTeal, false)
+	}
+	if card.Seed != 0 {
+		fonts.DrawTextCentered(img, i18n.Tf("seed", card.Seed), 14*unit, footerX, at(0.87), colors.DarkTeal, false)
+	}
+
+	return img
+}
+
+func (r *Renderer) drawQRCode(dst *ebiten.Image, url string, cx, cy, size float32) {
+	code, err := qrcode.New(url, qrcode.Medium)
+	if err != nil {
+		return
+	}
+	code.DisableBorder = true
+	code.ForegroundColor = color.Black
+	code.BackgroundColor = color.White
+
+	quiet := size * 0.08
+	r.drawRoundedRect(dst, cx-size/2-quiet, cy-size/2-quiet, size+2*quiet, size+2*quiet, quiet, color.NRGBA{255, 255, 255, 255})
+
+	qr := ebiten.NewImageFromImage(code.Image(int(size)))
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Scale(float64(size)/float64(qr.Bounds().Dx()), float64(size)/float64(qr.Bounds().Dy()))
+	op.GeoM.Translate(float64(cx-size/2), float64(cy-size/2))
+	dst.DrawImage(qr, op)
+}
+
+func min32(a, b float32) float32 {
+	if a < b {
+		return a
+	}
+	return b
+}
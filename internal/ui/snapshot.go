@@ -0,0 +1,24 @@
+package ui
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+	assets "github.com/ponyo877/suika-shaker/assets/image"
+	"github.com/ponyo877/suika-shaker/internal/snapshot"
+)
+
+// DrawSnapshot draws a board received over the network the way the sender's
+// own DrawBoard would have drawn it.
+func (r *Renderer) DrawSnapshot(screen *ebiten.Image, snap snapshot.Snapshot, paddingBottom float64) {
+	r.DrawBackground(screen, paddingBottom)
+	if snap.Right > 0 {
+		r.DrawArenaWalls(screen, float64(snap.Left), float64(snap.Right))
+	}
+
+	for _, b := range snap.Bodies {
+		kind := assets.Kind(b.Kind)
+		if !assets.Exists(kind) {
+			continue
+		}
+		r.DrawFruit(screen, kind, float64(b.X), float64(b.Y), b.Angle)
+	}
+}
@@ -16,35 +16,43 @@ var poppinsBoldTTF []byte
 //go:embed fonts/Poppins-Regular-subset.ttf
 var poppinsRegularTTF []byte
 
+//go:embed fonts/MPlus1p-Regular-subset.ttf
+var mplusRegularTTF []byte
+
 var (
-	poppinsBoldSource    *text.GoTextFaceSource
-	poppinsRegularSource *text.GoTextFaceSource
+	poppinsBoldSource    = newFaceSource(poppinsBoldTTF)
+	poppinsRegularSource = newFaceSource(poppinsRegularTTF)
+	mplusRegularSource   = newFaceSource(mplusRegularTTF)
 )
 
-func init() {
-	var err error
-	poppinsBoldSource, err = text.NewGoTextFaceSource(bytes.NewReader(poppinsBoldTTF))
+func newFaceSource(ttf []byte) *text.GoTextFaceSource {
+	source, err := text.NewGoTextFaceSource(bytes.NewReader(ttf))
 	if err != nil {
 		log.Fatal(err)
 	}
+	return source
+}
 
-	poppinsRegularSource, err = text.NewGoTextFaceSource(bytes.NewReader(poppinsRegularTTF))
-	if err != nil {
-		log.Fatal(err)
-	}
+type FontSet struct {
+	Bold     *text.GoTextFaceSource
+	Regular  *text.GoTextFaceSource
+	Fallback *text.GoTextFaceSource
 }
 
-func DrawTextCentered(screen *ebiten.Image, str string, size float64, x, y float64, clr color.Color, bold bool) {
-	source := poppinsRegularSource
-	if bold {
-		source = poppinsBoldSource
+func DefaultFontSet() FontSet {
+	return FontSet{
+		Bold:     poppinsBoldSource,
+		Regular:  poppinsRegularSource,
+		Fallback: mplusRegularSource,
 	}
+}
 
-	face := &text.GoTextFace{
-		Source: source,
-		Size:   size,
-	}
+func DrawTextCentered(screen *ebiten.Image, str string, size float64, x, y float64, clr color.Color, bold bool) {
+	DefaultFontSet().DrawTextCentered(screen, str, size, x, y, clr, bold)
+}
 
+func (f FontSet) DrawTextCentered(screen *ebiten.Image, str string, size float64, x, y float64, clr color.Color, bold bool) {
+	face := f.face(size, bold)
 	textWidth, textHeight := text.Measure(str, face, 0)
 
 	op := &text.DrawOptions{}
@@ -52,3 +60,31 @@ func DrawTextCentered(screen *ebiten.Image, str string, size float64, x, y float
 	op.ColorScale.ScaleWithColor(clr)
 	text.Draw(screen, str, face, op)
 }
+
+// DrawText draws str with its top left corner at (x, y), one line per
+// newline.
+func (f FontSet) DrawText(screen *ebiten.Image, str string, size float64, x, y float64, clr color.Color, bold bool) {
+	op := &text.DrawOptions{}
+	op.GeoM.Translate(x, y)
+	op.LineSpacing = size * 1.2
+	op.ColorScale.ScaleWithColor(clr)
+	text.Draw(screen, str, f.face(size, bold), op)
+}
+
+func (f FontSet) face(size float64, bold bool) text.Face {
+	source := f.Regular
+	if bold {
+		source = f.Bold
+	}
+
+	var face text.Face = &text.GoTextFace{
+		Source: source,
+		Size:   size,
+	}
+	if f.Fallback != nil {
+		if multi, err := text.NewMultiFace(face, &text.GoTextFace{Source: f.Fallback, Size: size}); err == nil {
+			face = multi
+		}
+	}
+	return face
+}
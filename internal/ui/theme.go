@@ -0,0 +1,112 @@
+package ui
+
+import (
+	"image/color"
+	"sort"
+)
+
+const (
+	ThemeLight        = "light"
+	ThemeDark         = "dark"
+	ThemeHighContrast = "high-contrast"
+)
+
+type Theme struct {
+	Name             string
+	Colors           ColorPalette
+	Fonts            FontSet
+	Dialog           DialogConfig
+	BoardBorderWidth float32
+}
+
+var themes = map[string]Theme{}
+
+func init() {
+	RegisterTheme(newLightTheme())
+	RegisterTheme(newDarkTheme())
+	RegisterTheme(newHighContrastTheme())
+}
+
+func newLightTheme() Theme {
+	return Theme{
+		Name:             ThemeLight,
+		Colors:           NewColorPalette(),
+		Fonts:            DefaultFontSet(),
+		Dialog:           NewDialogConfig(),
+		BoardBorderWidth: 10,
+	}
+}
+
+func newDarkTheme() Theme {
+	return Theme{
+		Name: ThemeDark,
+		Colors: ColorPalette{
+			Beige:      color.NRGBA{44, 48, 56, 255},
+			DarkTeal:   color.NRGBA{150, 210, 205, 255},
+			RedBrown:   color.NRGBA{214, 104, 96, 255},
+			White:      color.NRGBA{255, 255, 255, 255},
+			Black:      color.NRGBA{0, 0, 0, 255},
+			LightGreen: color.NRGBA{30, 38, 36, 255},
+			Cyan:       color.NRGBA{58, 108, 112, 255},
+			Overlay:    color.NRGBA{0, 0, 0, 160},
+		},
+		Fonts:            DefaultFontSet(),
+		Dialog:           NewDialogConfig(),
+		BoardBorderWidth: 10,
+	}
+}
+
+func newHighContrastTheme() Theme {
+	dialog := NewDialogConfig()
+	dialog.Radius = 6
+	dialog.RetryRadius = 4
+	dialog.BorderWidth = 14
+
+	fonts := DefaultFontSet()
+	fonts.Regular = fonts.Bold
+
+	return Theme{
+		Name: ThemeHighContrast,
+		Colors: ColorPalette{
+			Beige:      color.NRGBA{0, 0, 0, 255},
+			DarkTeal:   color.NRGBA{255, 255, 0, 255},
+			RedBrown:   color.NRGBA{0, 90, 255, 255},
+			White:      color.NRGBA{255, 255, 255, 255},
+			Black:      color.NRGBA{0, 0, 0, 255},
+			LightGreen: color.NRGBA{255, 255, 255, 255},
+			Cyan:       color.NRGBA{0, 0, 0, 255},
+			Overlay:    color.NRGBA{0, 0, 0, 200},
+		},
+		Fonts:            fonts,
+		Dialog:           dialog,
+		BoardBorderWidth: 14,
+	}
+}
+
+func RegisterTheme(theme Theme) {
+	themes[theme.Name] = theme
+}
+
+func LookupTheme(name string) (Theme, bool) {
+	theme, ok := themes[name]
+	return theme, ok
+}
+
+func ThemeNames() []string {
+	names := make([]string, 0, len(themes))
+	for name := range themes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func NextThemeName(current string) string {
+	names := ThemeNames()
+	for i, name := range names {
+		if name == current {
+			return names[(i+1)%len(names)]
+		}
+	}
+	return names[0]
+}
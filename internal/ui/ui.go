@@ -7,11 +7,13 @@ import (
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/vector"
 	assets "github.com/ponyo877/suika-shaker/assets/image"
+	"github.com/ponyo877/suika-shaker/internal/i18n"
+	"github.com/ponyo877/suika-shaker/internal/physics"
 )
 
 const (
-	ScreenWidth  = 480
-	ScreenHeight = 800
+	ScreenWidth  = physics.BoardWidth
+	ScreenHeight = physics.BoardHeight
 )
 
 type ButtonConfig struct {
@@ -23,22 +25,52 @@ type ButtonConfig struct {
 
 var (
 	SpeakerButtonConfig = ButtonConfig{X: ScreenWidth - 60, Y: 10, Width: 50, Height: 50}
+	HomeButtonConfig    = ButtonConfig{X: ScreenWidth - 120, Y: 10, Width: 50, Height: 50}
+	StartButtonConfig   = ButtonConfig{X: 125, Y: 650, Width: 230, Height: 50}
 )
 
+// ModeButton is one entry of the title screen's mode picker.
+type ModeButton struct {
+	Label    string
+	Detail   string
+	Selected bool
+}
+
+// ModeButtonConfig lays n mode buttons out as a two-column grid between the
+// title logo and the start button, centring an odd last button.
+func ModeButtonConfig(i, n int) ButtonConfig {
+	const (
+		width  = 190
+		height = 60
+		gap    = 8
+		top    = 350
+	)
+	x := float32(ScreenWidth/2 - width - gap/2)
+	switch {
+	case i == n-1 && n%2 == 1:
+		x = (ScreenWidth - width) / 2
+	case i%2 == 1:
+		x = ScreenWidth/2 + gap/2
+	}
+	return ButtonConfig{X: x, Y: top + float32(i/2)*(height+gap), Width: width, Height: height}
+}
+
 type DialogConfig struct {
-	Width        float32
-	Height       float32
-	X            float32
-	Y            float32
-	BorderWidth  float32
-	Radius       float32
-	ButtonY      float32
-	RetryX       float32
-	RetryWidth   float32
-	RetryHeight  float32
-	RetryRadius  float32
-	XButtonSize  float32
-	XButtonX     float32
+	Width          float32
+	Height         float32
+	X              float32
+	Y              float32
+	BorderWidth    float32
+	Radius         float32
+	ButtonY        float32
+	RetryX         float32
+	RetryWidth     float32
+	RetryHeight    float32
+	RetryRadius    float32
+	GIFX           float32
+	GIFWidth       float32
+	XButtonSize    float32
+	XButtonX       float32
 	XButtonCenterX float32
 	XButtonCenterY float32
 }
@@ -52,12 +84,14 @@ func NewDialogConfig() DialogConfig {
 		borderWidth  = 10
 		radius       = 25
 		buttonY      = dialogY + dialogHeight - 85
-		retryWidth   = 230
+		retryWidth   = 155
 		retryHeight  = 50
 		retryRadius  = 15
 		retryX       = dialogX + 25
+		gifWidth     = 60
+		gifX         = retryX + retryWidth + 10
 		xButtonSize  = 60
-		xButtonX     = retryX + retryWidth + 15
+		xButtonX     = dialogX + 270
 	)
 
 	return DialogConfig{
@@ -72,6 +106,8 @@ func NewDialogConfig() DialogConfig {
 		RetryWidth:     retryWidth,
 		RetryHeight:    retryHeight,
 		RetryRadius:    retryRadius,
+		GIFX:           gifX,
+		GIFWidth:       gifWidth,
 		XButtonSize:    xButtonSize,
 		XButtonX:       xButtonX,
 		XButtonCenterX: xButtonX + xButtonSize/2,
@@ -79,6 +115,10 @@ func NewDialogConfig() DialogConfig {
 	}
 }
 
+func (c DialogConfig) GIFButton() ButtonConfig {
+	return ButtonConfig{X: c.GIFX, Y: c.ButtonY, Width: c.GIFWidth, Height: c.RetryHeight}
+}
+
 type ColorPalette struct {
 	Beige     color.NRGBA
 	DarkTeal  color.NRGBA
@@ -87,6 +127,7 @@ type ColorPalette struct {
 	Black     color.NRGBA
 	LightGreen color.NRGBA
 	Cyan      color.NRGBA
+	Overlay   color.NRGBA
 }
 
 func NewColorPalette() ColorPalette {
@@ -98,21 +139,36 @@ func NewColorPalette() ColorPalette {
 		Black:      color.NRGBA{0, 0, 0, 255},
 		LightGreen: color.NRGBA{237, 248, 208, 255},
 		Cyan:       color.NRGBA{168, 230, 229, 255},
+		Overlay:    color.NRGBA{255, 255, 255, 178},
 	}
 }
 
 type Renderer struct {
-	colors ColorPalette
+	theme Theme
 }
 
 func NewRenderer() *Renderer {
+	theme, _ := LookupTheme(ThemeLight)
 	return &Renderer{
-		colors: NewColorPalette(),
+		theme: theme,
 	}
 }
 
+func (r *Renderer) Theme() Theme {
+	return r.theme
+}
+
+func (r *Renderer) SetTheme(theme Theme) {
+	r.theme = theme
+}
+
+func (r *Renderer) SetColorPalette(colors ColorPalette) {
+	r.theme.Colors = colors
+}
+
 func (r *Renderer) DrawBackground(screen *ebiten.Image, paddingBottom float64) {
-	screen.Fill(r.colors.Black)
+	colors := r.theme.Colors
+	screen.Fill(colors.Black)
 
 	var path vector.Path
 	path.MoveTo(0, 0)
@@ -121,13 +177,21 @@ func (r *Renderer) DrawBackground(screen *ebiten.Image, paddingBottom float64) {
 	path.LineTo(ScreenWidth, 0)
 	path.LineTo(0, 0)
 
-	r.fillPath(screen, path, r.colors.LightGreen)
-	r.strokePath(screen, path, r.colors.Cyan, 10)
+	r.fillPath(screen, path, colors.LightGreen)
+	r.strokePath(screen, path, colors.Cyan, r.theme.BoardBorderWidth)
+}
+
+// DrawArenaWalls shades the parts of the board outside a narrowed container.
+func (r *Renderer) DrawArenaWalls(screen *ebiten.Image, left, right float64) {
+	colors := r.theme.Colors
+	r.drawRoundedRect(screen, 0, 0, float32(left), ScreenHeight, 0, colors.Cyan)
+	r.drawRoundedRect(screen, float32(right), 0, ScreenWidth-float32(right), ScreenHeight, 0, colors.Cyan)
+	r.strokePath(screen, r.createRoundedRectPath(float32(left), 0, float32(right-left), ScreenHeight, 0), colors.DarkTeal, r.theme.BoardBorderWidth/2)
 }
 
 func (r *Renderer) DrawFruit(screen *ebiten.Image, kind assets.Kind, x, y, angle float64) {
 	imgSet := assets.Get(kind)
-	img := imgSet.EbitenImage
+	img := fruitTexture(kind)
 	size := img.Bounds().Size()
 
 	op := &ebiten.DrawImageOptions{}
@@ -144,9 +208,9 @@ func (r *Renderer) DrawSpeakerButton(screen *ebiten.Image, muted bool) {
 
 	var icon *ebiten.Image
 	if muted {
-		icon = assets.GetIcon(assets.Muted)
+		icon = iconTexture(assets.Muted)
 	} else {
-		icon = assets.GetIcon(assets.Speaker)
+		icon = iconTexture(assets.Speaker)
 	}
 
 	iconBounds := icon.Bounds()
@@ -166,8 +230,10 @@ func (r *Renderer) DrawSpeakerButton(screen *ebiten.Image, muted bool) {
 	screen.DrawImage(icon, op)
 }
 
-func (r *Renderer) DrawGameOverDialog(screen *ebiten.Image, score, watermelonHits int) {
-	cfg := NewDialogConfig()
+func (r *Renderer) DrawGameOverDialog(screen *ebiten.Image, score, watermelonHits int, showGIF bool) {
+	cfg := r.theme.Dialog
+	colors := r.theme.Colors
+	fonts := r.theme.Fonts
 
 	var overlayPath vector.Path
 	overlayPath.MoveTo(0, 0)
@@ -175,26 +241,32 @@ func (r *Renderer) DrawGameOverDialog(screen *ebiten.Image, score, watermelonHit
 	overlayPath.LineTo(ScreenWidth, ScreenHeight)
 	overlayPath.LineTo(0, ScreenHeight)
 	overlayPath.Close()
-	r.fillPath(screen, overlayPath, color.NRGBA{255, 255, 255, 178})
+	r.fillPath(screen, overlayPath, colors.Overlay)
 
-	r.drawRoundedRect(screen, cfg.X, cfg.Y, cfg.Width, cfg.Height, cfg.Radius, r.colors.Beige)
-	r.strokePath(screen, r.createRoundedRectPath(cfg.X, cfg.Y, cfg.Width, cfg.Height, cfg.Radius), r.colors.DarkTeal, cfg.BorderWidth)
+	r.drawRoundedRect(screen, cfg.X, cfg.Y, cfg.Width, cfg.Height, cfg.Radius, colors.Beige)
+	r.strokePath(screen, r.createRoundedRectPath(cfg.X, cfg.Y, cfg.Width, cfg.Height, cfg.Radius), colors.DarkTeal, cfg.BorderWidth)
 
 	centerX := cfg.X + cfg.Width/2
-	DrawTextCentered(screen, "GAME OVER", 42, float64(centerX), float64(cfg.Y+60), r.colors.RedBrown, true)
-	DrawTextCentered(screen, "SCORE", 18, float64(centerX), float64(cfg.Y+120), r.colors.DarkTeal, true)
-	DrawTextCentered(screen, fmt.Sprintf("%d", score), 60, float64(centerX), float64(cfg.Y+175), r.colors.DarkTeal, true)
-	DrawTextCentered(screen, "WATERMELONS HITS", 16, float64(centerX), float64(cfg.Y+235), r.colors.DarkTeal, true)
-	DrawTextCentered(screen, fmt.Sprintf("%d", watermelonHits), 60, float64(centerX), float64(cfg.Y+290), r.colors.DarkTeal, true)
-
-	r.drawRoundedRect(screen, cfg.RetryX, cfg.ButtonY, cfg.RetryWidth, cfg.RetryHeight, cfg.RetryRadius, r.colors.RedBrown)
-	DrawTextCentered(screen, "RETRY", 28, float64(cfg.RetryX+cfg.RetryWidth/2), float64(cfg.ButtonY+cfg.RetryHeight/2), r.colors.White, true)
+	fonts.DrawTextCentered(screen, i18n.T("game_over"), 42, float64(centerX), float64(cfg.Y+60), colors.RedBrown, true)
+	fonts.DrawTextCentered(screen, i18n.T("score"), 18, float64(centerX), float64(cfg.Y+120), colors.DarkTeal, true)
+	fonts.DrawTextCentered(screen, fmt.Sprintf("%d", score), 60, float64(centerX), float64(cfg.Y+175), colors.DarkTeal, true)
+	fonts.DrawTextCentered(screen, i18n.T("watermelon_hits"), 16, float64(centerX), float64(cfg.Y+235), colors.DarkTeal, true)
+	fonts.DrawTextCentered(screen, fmt.Sprintf("%d", watermelonHits), 60, float64(centerX), float64(cfg.Y+290), colors.DarkTeal, true)
+
+	r.drawRoundedRect(screen, cfg.RetryX, cfg.ButtonY, cfg.RetryWidth, cfg.RetryHeight, cfg.RetryRadius, colors.RedBrown)
+	fonts.DrawTextCentered(screen, i18n.T("retry"), 28, float64(cfg.RetryX+cfg.RetryWidth/2), float64(cfg.ButtonY+cfg.RetryHeight/2), colors.White, true)
+
+	if showGIF {
+		gif := cfg.GIFButton()
+		r.drawRoundedRect(screen, gif.X, gif.Y, gif.Width, gif.Height, cfg.RetryRadius, colors.DarkTeal)
+		fonts.DrawTextCentered(screen, "GIF", 20, float64(gif.X+gif.Width/2), float64(gif.Y+gif.Height/2), colors.Beige, true)
+	}
 }
 
 func (r *Renderer) DrawTitleScreen(screen *ebiten.Image, paddingBottom float64) {
 	r.DrawBackground(screen, paddingBottom)
 
-	titleLogo := assets.GetIcon(assets.TitleLogo)
+	titleLogo := iconTexture(assets.TitleLogo)
 	titleLogoBounds := titleLogo.Bounds()
 
 	const maxLogoWidth = 400.0
@@ -214,6 +286,46 @@ func (r *Renderer) DrawTitleScreen(screen *ebiten.Image, paddingBottom float64)
 	screen.DrawImage(titleLogo, logoOp)
 }
 
+func (r *Renderer) DrawModePicker(screen *ebiten.Image, buttons []ModeButton) {
+	colors := r.theme.Colors
+	fonts := r.theme.Fonts
+
+	for i, b := range buttons {
+		cfg := ModeButtonConfig(i, len(buttons))
+		bg, fg := colors.Beige, colors.DarkTeal
+		if b.Selected {
+			bg, fg = colors.DarkTeal, colors.Beige
+		}
+		r.drawRoundedRect(screen, cfg.X, cfg.Y, cfg.Width, cfg.Height, 15, bg)
+		r.strokePath(screen, r.createRoundedRectPath(cfg.X, cfg.Y, cfg.Width, cfg.Height, 15), colors.DarkTeal, 4)
+
+		centerX := float64(cfg.X + cfg.Width/2)
+		if b.Detail == "" {
+			fonts.DrawTextCentered(screen, b.Label, 20, centerX, float64(cfg.Y+cfg.Height/2), fg, true)
+			continue
+		}
+		fonts.DrawTextCentered(screen, b.Label, 20, centerX, float64(cfg.Y+cfg.Height*0.36), fg, true)
+		fonts.DrawTextCentered(screen, b.Detail, 14, centerX, float64(cfg.Y+cfg.Height*0.72), fg, false)
+	}
+}
+
+func (r *Renderer) DrawStartButton(screen *ebiten.Image) {
+	cfg := StartButtonConfig
+	r.drawRoundedRect(screen, cfg.X, cfg.Y, cfg.Width, cfg.Height, 15, r.theme.Colors.RedBrown)
+	r.theme.Fonts.DrawTextCentered(screen, i18n.T("start"), 28, float64(cfg.X+cfg.Width/2), float64(cfg.Y+cfg.Height/2), r.theme.Colors.White, true)
+}
+
+// DrawHomeButton draws the button that leaves a round for the title screen,
+// as three bars so it needs no icon asset.
+func (r *Renderer) DrawHomeButton(screen *ebiten.Image) {
+	cfg := HomeButtonConfig
+	r.drawRoundedRect(screen, cfg.X, cfg.Y, cfg.Width, cfg.Height, 12, r.theme.Colors.Beige)
+	for i := 0; i < 3; i++ {
+		y := cfg.Y + cfg.Height*float32(i+1)/4
+		r.drawRoundedRect(screen, cfg.X+12, y-2.5, cfg.Width-24, 5, 2.5, r.theme.Colors.DarkTeal)
+	}
+}
+
 func (r *Renderer) drawRoundedRect(screen *ebiten.Image, x, y, width, height, radius float32, clr color.NRGBA) {
 	path := r.createRoundedRectPath(x, y, width, height, radius)
 	r.fillPath(screen, path, clr)
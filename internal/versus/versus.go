@@ -0,0 +1,135 @@
+package versus
+
+import (
+	"math"
+
+	assets "github.com/ponyo877/suika-shaker/assets/image"
+	"github.com/ponyo877/suika-shaker/internal/mode"
+	"github.com/ponyo877/suika-shaker/internal/physics"
+	"github.com/ponyo877/suika-shaker/internal/sim"
+)
+
+const (
+	Players = 2
+
+	NoWinner = -1
+	Draw     = Players
+
+	// maxTilt is how far, in radians, full stick or key input turns gravity.
+	maxTilt = math.Pi / 3
+)
+
+// Mode is the rule set both boards play under: classic drops, with the
+// match decided by who overflows first rather than by a high score.
+func Mode() mode.Mode {
+	m, _ := mode.Lookup(mode.Classic)
+	m.Name = mode.Versus
+	m.Label = "mode_versus"
+	m.Scored = false
+	// Knocking fruits out of the box is how a board overflows here, so
+	// escapes are never put back.
+	m.RecoverEscapes = false
+	return m
+}
+
+// Control is one player's input for a tick: tilt in [-1, 1] and whether
+// they are shaking the board.
+type Control struct {
+	Tilt  float64
+	Shake bool
+}
+
+// Input turns a control into the gravity a board falls under: tilting turns
+// gravity sideways and shaking briefly flips it upwards.
+func (c Control) Input() sim.Input {
+	g := float64(physics.DefaultGravityY)
+	if c.Shake {
+		g = -g
+	}
+	angle := math.Max(-1, math.Min(1, c.Tilt)) * maxTilt
+	return sim.Input{
+		GravityX: int(math.Round(math.Abs(g) * math.Sin(angle))),
+		GravityY: int(math.Round(g * math.Cos(angle))),
+	}
+}
+
+// Garbage is the number of grapes a merge of kind sends to the opponent; only
+// merges of a pear or larger attack.
+func Garbage(kind assets.Kind) int {
+	return max(0, int(kind-assets.Apple))
+}
+
+// Match runs two independent boards from the same seed, so both players see
+// the same fruits, and trades garbage between them.
+type Match struct {
+	Boards   [Players]*sim.Simulation
+	Winner   int
+	Sent     [Players]int
+	OnMerge  func(player int, kind assets.Kind)
+	OnDanger func(player int)
+}
+
+func NewMatch(seed int64) *Match {
+	m := &Match{Winner: NoWinner}
+	for i := range m.Boards {
+		player := i
+		board := sim.New(Mode(), seed)
+		board.State.ShowTitleScreen = false
+		board.Events = sim.Events{
+			OnMerge: func(kind assets.Kind) { m.merged(player, kind) },
+			OnDanger: func() {
+				if m.OnDanger != nil {
+					m.OnDanger(player)
+				}
+			},
+		}
+		m.Boards[i] = board
+	}
+	return m
+}
+
+func (m *Match) merged(player int, kind assets.Kind) {
+	if n := Garbage(kind); n > 0 {
+		m.Boards[1-player].AddGarbage(n)
+		m.Sent[player] += n
+	}
+	if m.OnMerge != nil {
+		m.OnMerge(player, kind)
+	}
+}
+
+func (m *Match) Over() bool {
+	return m.Winner != NoWinner
+}
+
+// Step advances both boards one tick. Once a board overflows the other
+// player wins and both boards freeze.
+func (m *Match) Step(controls [Players]Control) {
+	if m.Over() {
+		return
+	}
+
+	for i, board := range m.Boards {
+		board.Step(controls[i].Input())
+	}
+
+	lost0, lost1 := m.Boards[0].State.GameOver, m.Boards[1].State.GameOver
+	switch {
+	case lost0 && lost1:
+		m.Winner = Draw
+	case lost0:
+		m.Winner = 1
+	case lost1:
+		m.Winner = 0
+	default:
+		return
+	}
+
+	for _, board := range m.Boards {
+		if !board.State.ShowGameOverDialog {
+			board.State.TriggerGameOver()
+			board.State.PrepareGameOverDialog()
+		}
+		board.Physics.StopAllBodies()
+	}
+}
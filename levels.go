@@ -0,0 +1,137 @@
+package main
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/ponyo877/suika-shaker/assets/sound"
+	"github.com/ponyo877/suika-shaker/internal/i18n"
+	"github.com/ponyo877/suika-shaker/internal/level"
+	"github.com/ponyo877/suika-shaker/internal/physics"
+	"github.com/ponyo877/suika-shaker/internal/ui"
+)
+
+func (g *Game) openLevelSelect() {
+	g.state.ShowTitleScreen = false
+	g.state.ShowLevelSelect = true
+	g.selectMode(level.Mode())
+	sound.StopBackgroundMusic()
+	hideShareButton()
+}
+
+func (g *Game) startLevel(i int) {
+	levels := level.All()
+	if i < 0 || i >= len(levels) || !g.progress.Unlocked(i) {
+		return
+	}
+
+	l := levels[i]
+	g.selectMode(l.Mode())
+	g.sim.Level = &l
+	g.levelIndex = i
+	g.state.ShowLevelSelect = false
+	g.resetGame()
+}
+
+func (g *Game) handleLevelSelectInput() {
+	if clicked, x, y := g.inputHandler.CheckMouseClick(); clicked {
+		g.handleLevelSelectClick(x, y)
+	}
+
+	for _, touch := range g.inputHandler.CheckTouchInput() {
+		g.handleLevelSelectClick(touch.X, touch.Y)
+	}
+}
+
+func (g *Game) handleLevelSelectClick(x, y int) {
+	if g.inputHandler.IsButtonClicked(x, y, ui.HomeButtonConfig) {
+		g.returnToTitle()
+		return
+	}
+
+	for i := range level.All() {
+		if g.inputHandler.IsButtonClicked(x, y, ui.LevelButtonConfig(i)) {
+			g.startLevel(i)
+			return
+		}
+	}
+}
+
+func (g *Game) handleLevelResultClick(x, y int) {
+	dialog := g.renderer.Theme().Dialog
+
+	if g.inputHandler.IsRetryButtonClicked(x, y, dialog) {
+		g.resetGame()
+		return
+	}
+
+	if g.inputHandler.IsButtonClicked(x, y, dialog.NextButton()) {
+		if g.hasNextLevel() {
+			g.startLevel(g.levelIndex + 1)
+		} else {
+			g.openLevelSelect()
+		}
+	}
+}
+
+func (g *Game) hasNextLevel() bool {
+	return g.state.LevelCleared && g.levelIndex+1 < len(level.All())
+}
+
+func (g *Game) finishLevel() {
+	if !g.state.LevelCleared {
+		return
+	}
+	if err := g.progress.Record(g.sim.Level.ID, g.state.Stars); err != nil {
+		log.Printf("failed to save level progress: %v", err)
+	}
+}
+
+func (g *Game) drawLevelSelect(screen *ebiten.Image) {
+	var buttons []ui.LevelButton
+	for i, l := range level.All() {
+		buttons = append(buttons, ui.LevelButton{
+			Label:  fmt.Sprintf("%d", i+1),
+			Stars:  g.progress[l.ID],
+			Locked: !g.progress.Unlocked(i),
+		})
+	}
+
+	total := i18n.Tf("stars_total", g.progress.TotalStars(), len(buttons)*level.MaxStars)
+	g.renderer.DrawLevelSelect(screen, i18n.T("level_select"), total, buttons, physics.PaddingBottom)
+	g.renderer.DrawHomeButton(screen)
+}
+
+// drawLevelOverlay draws the objective banner during a level and the result
+// dialog once it ends.
+func (g *Game) drawLevelOverlay(screen *ebiten.Image) {
+	objective := objectiveText(g.sim.Level.Objective)
+
+	if !g.state.ShowGameOverDialog {
+		progress := i18n.Tf("level_number", g.levelIndex+1) + "  " + i18n.Tf("drops_left", g.sim.QueueLeft())
+		g.renderer.DrawLevelBanner(screen, objective, progress)
+		return
+	}
+
+	title, next := i18n.T("level_failed"), i18n.T("levels")
+	if g.state.LevelCleared {
+		title = i18n.T("level_clear")
+	}
+	if g.hasNextLevel() {
+		next = i18n.T("next")
+	}
+	g.renderer.DrawResultDialog(screen, title, objective, g.state.Stars, i18n.T("retry"), next)
+}
+
+func objectiveText(o level.Objective) string {
+	switch o.Type {
+	case level.ObjectiveMake:
+		return i18n.Tf("objective_make", i18n.T("fruit_"+o.Kind.String()), o.Count)
+	case level.ObjectiveClear:
+		return i18n.Tf("objective_clear", i18n.T("fruit_"+o.Kind.String()))
+	case level.ObjectiveScore:
+		return i18n.Tf("objective_score", o.Score)
+	}
+	return ""
+}
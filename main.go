@@ -1,10 +1,11 @@
 package main
 
 import (
+	"bytes"
+	"flag"
 	"fmt"
 	"log"
-	"math"
-	"math/rand"
+	"time"
 
 	"github.com/demouth/ebitencp"
 	"github.com/hajimehoshi/ebiten/v2"
@@ -12,83 +13,250 @@ import (
 	"github.com/jakecoffman/cp/v2"
 	assets "github.com/ponyo877/suika-shaker/assets/image"
 	"github.com/ponyo877/suika-shaker/assets/sound"
+	"github.com/ponyo877/suika-shaker/internal/achievement"
+	"github.com/ponyo877/suika-shaker/internal/clip"
+	"github.com/ponyo877/suika-shaker/internal/daily"
 	"github.com/ponyo877/suika-shaker/internal/gamestate"
+	"github.com/ponyo877/suika-shaker/internal/i18n"
 	"github.com/ponyo877/suika-shaker/internal/input"
+	"github.com/ponyo877/suika-shaker/internal/level"
+	"github.com/ponyo877/suika-shaker/internal/mode"
+	"github.com/ponyo877/suika-shaker/internal/online"
 	"github.com/ponyo877/suika-shaker/internal/physics"
+	"github.com/ponyo877/suika-shaker/internal/replay"
+	"github.com/ponyo877/suika-shaker/internal/share"
+	"github.com/ponyo877/suika-shaker/internal/sim"
 	"github.com/ponyo877/suika-shaker/internal/ui"
+	"github.com/ponyo877/suika-shaker/internal/versus"
 )
 
 var currentGame *Game
 
 type Game struct {
-	state          *gamestate.State
-	physicsManager *physics.Manager
-	renderer       *ui.Renderer
-	inputHandler   *input.Handler
-	drawer         *ebitencp.Drawer
-	debug          bool
+	sim          *sim.Simulation
+	state        *gamestate.State
+	replay       *replay.Replay
+	renderer     *ui.Renderer
+	inputHandler *input.Handler
+	drawer       *ebitencp.Drawer
+	recorder     *clip.Recorder
+	screenshot   *ebiten.Image // the board as the round ended, for sharing
+	challenge    daily.Challenge
+	progress     level.Progress
+	achievements *achievement.Progress
+	toasts       []toast
+	levelIndex   int
+	match        *versus.Match
+	boards       [versus.Players]*ebiten.Image
+	room         *onlineRoom
+	stream       *online.Client
+	watch        *spectator
+	skinPalette  *ui.ColorPalette // kept across theme changes
+	debug        bool
 }
 
-func NewGame() *Game {
-	physManager := physics.NewManager()
-
-	assets.ForEach(func(kind assets.Kind, _ assets.ImageSet) {
-		ct := cp.CollisionType(kind)
-		physManager.GetSpace().NewCollisionHandler(ct, ct).BeginFunc = createCollisionHandler(physManager)
-	})
-
-	state := gamestate.NewState()
-	state.NextFruit = gamestate.NextFruit{
-		Kind:  assets.Grape,
-		X:     ui.ScreenWidth / 2,
-		Y:     ui.ScreenHeight - physics.ContainerHeight + 10,
-		Angle: 0,
-	}
+func NewGame(m mode.Mode) *Game {
+	seed := time.Now().UnixNano()
+	simulation := sim.New(m, seed)
 
 	drawer := ebitencp.NewDrawer(ui.ScreenWidth, ui.ScreenHeight)
 	drawer.FlipYAxis = true
 
-	return &Game{
-		state:          state,
-		physicsManager: physManager,
-		renderer:       ui.NewRenderer(),
-		inputHandler:   input.NewHandler(),
-		drawer:         drawer,
-		debug:          false,
+	g := &Game{
+		sim:          simulation,
+		state:        simulation.State,
+		replay:       replay.New(m, seed),
+		renderer:     ui.NewRenderer(),
+		inputHandler: input.NewHandler(),
+		drawer:       drawer,
+		recorder:     clip.NewRecorder(ui.ScreenWidth, ui.ScreenHeight, clip.DefaultSeconds, clip.DefaultFPS, clip.DefaultScale),
+		progress:     level.LoadProgress(),
+		achievements: achievement.Load(),
+		debug:        false,
 	}
+	simulation.Events = sim.Events{
+		OnMerge:    playMergeSound,
+		OnGameOver: g.onGameOver,
+		OnDanger:   sound.PlayWarning,
+		OnPowerUp:  func(assets.Kind) { sound.PlayJoin() },
+	}
+	return g
 }
 
 func (g *Game) Update() error {
-	g.state.IncrementCount()
-
+	if g.watch != nil {
+		g.updateWatch()
+		return nil
+	}
+	if g.match != nil {
+		g.updateMatch()
+		return nil
+	}
+	if g.room != nil {
+		g.updateRoom()
+		return nil
+	}
 	if g.state.ShowTitleScreen {
+		g.handleTitleInput()
+		return nil
+	}
+	if g.state.ShowLevelSelect {
+		g.handleLevelSelectInput()
+		return nil
+	}
+	if g.state.ShowAchievements {
+		g.handleAchievementsInput()
+		return nil
+	}
+	if g.state.ShowEncyclopedia {
+		g.handleEncyclopediaInput()
 		return nil
 	}
 
-	g.updatePhysics()
 	g.handleInput()
-	g.updateDropLogic()
-	g.updateAnimation()
-	g.checkGameOver()
+	g.stepGame()
 
 	return nil
 }
 
-func (g *Game) updatePhysics() {
+// stepGame advances the board one tick with this frame's input, recording it
+// until the round is over.
+func (g *Game) stepGame() {
 	ax, ay, _ := getAcceleration()
-	gravityX := ax * 100
-	gravityY := -ay * 100
+	in := sim.GravityInput(ax, ay)
+	in.PointerX, in.PointerY, in.Pressed = g.inputHandler.Pointer()
+	if g.state.ShowGameOverDialog {
+		g.sim.Step(in)
+		return
+	}
 
-	if ax == 0 && ay == 0 {
-		gravityY = physics.DefaultGravityY
+	g.replay.Record(in)
+	g.sim.Step(in)
+	g.checkAchievements()
+	if g.stream != nil {
+		g.sendFrame()
+	}
+}
+
+func (g *Game) handleTitleInput() {
+	if g.inputHandler.CheckStartKey() {
+		g.startGame()
+		return
+	}
+
+	if clicked, x, y := g.inputHandler.CheckMouseClick(); clicked {
+		g.handleTitleClick(x, y)
 	}
 
-	g.physicsManager.SetGravity(gravityX, gravityY)
-	g.drawer.HandleMouseEvent(g.physicsManager.GetSpace())
-	g.physicsManager.Step(1 / 60.0)
+	for _, touch := range g.inputHandler.CheckTouchInput() {
+		g.handleTitleClick(touch.X, touch.Y)
+	}
+}
+
+func (g *Game) handleTitleClick(x, y int) {
+	modes := g.modes()
+	for i, m := range modes {
+		if g.inputHandler.IsButtonClicked(x, y, ui.ModeButtonConfig(i, len(modes))) {
+			g.selectMode(m)
+			return
+		}
+	}
+
+	if g.inputHandler.IsButtonClicked(x, y, ui.StartButtonConfig) {
+		g.startGame()
+	}
+	if g.inputHandler.IsButtonClicked(x, y, ui.AchievementsButtonConfig) {
+		g.openAchievements()
+	}
+	if g.inputHandler.IsButtonClicked(x, y, ui.EncyclopediaButtonConfig) {
+		g.openEncyclopedia()
+	}
+}
+
+// modes lists the title screen's choices: the fixed modes plus today's daily
+// challenge, the puzzle levels, local versus and, when there is a server to
+// play on, online rooms.
+func (g *Game) modes() []mode.Mode {
+	modes := append(mode.All(), daily.Today().Mode, level.Mode(), versus.Mode())
+	if leaderboardURL() != "" {
+		modes = append(modes, online.Mode(online.DefaultDuration))
+	}
+	return modes
+}
+
+func lookupMode(name string) (mode.Mode, bool) {
+	switch name {
+	case mode.Daily:
+		return daily.Today().Mode, true
+	case mode.Puzzle:
+		return level.Mode(), true
+	case mode.Versus:
+		return versus.Mode(), true
+	case mode.Online:
+		return online.Mode(online.DefaultDuration), true
+	}
+	return mode.Lookup(name)
+}
+
+func (g *Game) selectMode(m mode.Mode) {
+	g.sim.Level = nil
+	g.sim.Mode = m
+	g.state.Mode = m.Name
+}
+
+// startGame leaves the title screen for a round of the selected mode. It
+// returns false if that is today's daily challenge and it was already played.
+func (g *Game) startGame() bool {
+	switch g.sim.Mode.Name {
+	case mode.Puzzle:
+		g.openLevelSelect()
+		return true
+	case mode.Versus:
+		g.startMatch()
+		return true
+	case mode.Online:
+		return g.joinRoom()
+	}
+
+	if g.sim.Mode.Name == mode.Daily {
+		g.challenge = daily.Today()
+		if _, played := daily.Played(g.challenge.Date); played {
+			return false
+		}
+		g.selectMode(g.challenge.Mode)
+		if err := (daily.Attempt{Date: g.challenge.Date}).Save(); err != nil {
+			log.Printf("failed to record daily attempt: %v", err)
+		}
+	}
+
+	g.state.ShowTitleScreen = false
+	g.resetGame()
+	return true
+}
+
+func (g *Game) returnToTitle() {
+	if g.match != nil {
+		g.match = nil
+		ebiten.SetWindowSize(ui.ScreenWidth, ui.ScreenHeight)
+	}
+	if g.room != nil {
+		g.leaveRoom()
+	}
+	g.state.ShowTitleScreen = true
+	g.state.ShowLevelSelect = false
+	g.state.ShowAchievements = false
+	g.state.ShowEncyclopedia = false
+	g.recorder.Reset()
+	sound.StopBackgroundMusic()
+	hideShareButton()
+	showTitleScreen()
 }
 
 func (g *Game) handleInput() {
+	if g.inputHandler.CheckThemeToggle() {
+		g.setTheme(ui.NextThemeName(g.renderer.Theme().Name))
+	}
+
 	if clicked, x, y := g.inputHandler.CheckMouseClick(); clicked {
 		g.handleButtonClick(x, y)
 	}
@@ -104,126 +272,254 @@ func (g *Game) handleButtonClick(x, y int) {
 		sound.SetMuted(g.state.IsMuted())
 	}
 
-	if g.state.ShowGameOverDialog && g.inputHandler.IsRetryButtonClicked(x, y) {
-		g.resetGame()
+	if g.inputHandler.IsButtonClicked(x, y, ui.HomeButtonConfig) {
+		g.leaveRound()
+		if g.sim.Level != nil {
+			g.openLevelSelect()
+		} else {
+			g.returnToTitle()
+		}
+		return
 	}
-}
 
-func (g *Game) checkGameOver() {
-	if g.state.ShowGameOverDialog {
+	if g.state.ShowGameOverDialog && g.sim.Level != nil {
+		g.handleLevelResultClick(x, y)
 		return
 	}
 
-	if g.physicsManager.CheckBodiesOutOfBounds() {
-		g.state.TriggerGameOver()
-		if g.state.GameOverSE {
-			sound.PlayGameOver()
-			sound.StopBackgroundMusic()
+	if g.state.ShowGameOverDialog && g.inputHandler.IsRetryButtonClicked(x, y, g.renderer.Theme().Dialog) {
+		if g.sim.Mode.Name == mode.Daily {
+			g.returnToTitle()
+			return
 		}
+		g.resetGame()
 	}
 
-	if g.state.GameOver && !g.state.ShowGameOverDialog {
-		g.state.PrepareGameOverDialog()
-		g.physicsManager.StopAllBodies()
+	if g.state.ShowGameOverDialog && g.recorder.Len() > 0 && g.inputHandler.IsButtonClicked(x, y, g.renderer.Theme().Dialog.GIFButton()) {
+		g.exportClip()
 	}
 }
 
-func (g *Game) updateDropLogic() {
-	if g.state.ShowGameOverDialog {
-		return
+func (g *Game) onGameOver() {
+	if g.state.LevelCleared {
+		sound.PlaySuikaJoin()
+	} else {
+		sound.PlayGameOver()
 	}
+	sound.StopBackgroundMusic()
 
-	g.state.IncrementDropCount()
+	g.replay.Finish(g.state.FinalScore, g.state.FinalWatermelonHits)
+	exportReplay(g.replay, g.state.FinishedAt)
+	g.finishAchievements()
 
-	if g.state.DropCount >= 45 {
-		g.dropFruit()
-		g.state.ResetDropCount()
+	if g.sim.Mode.Name == mode.Daily {
+		g.finishDaily()
+	}
+	if g.sim.Level != nil {
+		g.finishLevel()
+	}
+	if g.room != nil {
+		g.finishRound()
 	}
 }
 
-func (g *Game) updateAnimation() {
-	g.state.NextFruit.Angle += 0.01
-}
+func (g *Game) finishDaily() {
+	attempt := daily.Attempt{Date: g.challenge.Date, Score: g.state.FinalScore, Finished: true}
+	if err := attempt.Save(); err != nil {
+		log.Printf("failed to record daily attempt: %v", err)
+	}
 
-func (g *Game) dropFruit() {
-	if !g.physicsManager.CanSpawnAt(g.state.NextFruit.X, g.state.NextFruit.Y, physics.SpawnCheckRadius) {
-		g.state.SpawnFailCount++
-		if g.state.SpawnFailCount >= physics.MaxSpawnFailures {
-			g.state.TriggerGameOver()
-			if g.state.GameOverSE {
-				sound.PlayGameOver()
-				sound.StopBackgroundMusic()
-			}
-		}
+	baseURL := leaderboardURL()
+	if baseURL == "" {
 		return
 	}
-
-	g.state.SpawnFailCount = 0
-
-	addData := physics.AddShapeData{
-		Kind:  g.state.NextFruit.Kind,
-		Pos:   cp.Vector{X: g.state.NextFruit.X, Y: g.state.NextFruit.Y},
-		Angle: g.state.NextFruit.Angle,
+	var buf bytes.Buffer
+	if err := g.replay.Write(&buf); err != nil {
+		log.Printf("failed to encode replay: %v", err)
+		return
+	}
+	entry := daily.Entry{
+		Date:           g.challenge.Date,
+		Name:           playerName(),
+		Score:          g.state.FinalScore,
+		WatermelonHits: g.state.FinalWatermelonHits,
+		Seed:           g.challenge.Seed,
+		Replay:         buf.Bytes(),
 	}
-	g.physicsManager.GetSpace().AddPostStepCallback(
-		physics.CreateAddShapeCallback(g.physicsManager),
-		g.state.NextFruit.Kind,
-		addData,
-	)
+	go func() {
+		if err := daily.Submit(baseURL, entry); err != nil {
+			log.Printf("failed to submit daily score: %v", err)
+		}
+	}()
+}
 
-	g.state.NextFruit.Kind = assets.Kind(rand.Intn(2) + int(assets.Min))
-	g.state.NextFruit.X = float64(rand.Intn(ui.ScreenWidth-100) + 50)
-	g.state.NextFruit.Y = float64(rand.Intn(ui.ScreenHeight-100) + 50)
-	g.state.NextFruit.Angle = rand.Float64() * 2 * math.Pi
+func playMergeSound(kind assets.Kind) {
+	if kind == assets.Melon || kind == assets.Watermelon {
+		sound.PlaySuikaJoin()
+	} else {
+		sound.PlayJoin()
+	}
 }
 
 func (g *Game) Draw(screen *ebiten.Image) {
+	defer g.drawToast(screen)
+	if g.watch != nil {
+		g.drawWatch(screen)
+		return
+	}
+	if g.match != nil {
+		g.drawMatch(screen)
+		return
+	}
+	if g.room != nil {
+		g.drawRoom(screen)
+		return
+	}
 	if g.state.ShowTitleScreen {
 		g.renderer.DrawTitleScreen(screen, physics.PaddingBottom)
+		g.renderer.DrawModePicker(screen, g.modeButtons())
+		g.renderer.DrawStartButton(screen)
+		g.renderer.DrawAchievementsButton(screen, i18n.T("achievements"))
+		g.renderer.DrawEncyclopediaButton(screen, i18n.T("encyclopedia"))
+		return
+	}
+	if g.state.ShowLevelSelect {
+		g.drawLevelSelect(screen)
+		return
+	}
+	if g.state.ShowAchievements {
+		g.drawAchievements(screen)
+		return
+	}
+	if g.state.ShowEncyclopedia {
+		g.drawEncyclopedia(screen)
 		return
 	}
 
-	g.renderer.DrawBackground(screen, physics.PaddingBottom)
-
-	g.physicsManager.GetSpace().EachShape(func(shape *cp.Shape) {
-		if polyShape, ok := shape.Class.(*cp.PolyShape); ok {
-			vec := polyShape.Body().Position()
-			kind := polyShape.Body().UserData.(assets.Kind)
-			g.renderer.DrawFruit(screen, kind, vec.X, vec.Y-physics.PaddingBottom, polyShape.Body().Angle())
-		}
-	})
+	g.renderer.DrawBoard(screen, g.sim)
 
 	if g.debug {
-		cp.DrawSpace(g.physicsManager.GetSpace(), g.drawer.WithScreen(screen))
+		cp.DrawSpace(g.sim.Physics.GetSpace(), g.drawer.WithScreen(screen))
 	}
 
-	ebitenutil.DebugPrint(screen, fmt.Sprintf(
-		"FPS: %0.2f  The Go gopher was designed by Renee French.\nScore: %d\nHiScore: %d",
-		ebiten.ActualFPS(),
-		g.state.Score,
-		g.state.HiScore,
-	))
+	if !g.state.ShowGameOverDialog {
+		g.recorder.Capture(screen)
+	}
+
+	captureBoard := g.state.ShowGameOverDialog && g.screenshot == nil
+	if captureBoard {
+		g.screenshot = ebiten.NewImage(ui.ScreenWidth, ui.ScreenHeight)
+		g.screenshot.DrawImage(screen, nil)
+	}
+
+	hud := fmt.Sprintf("FPS: %0.2f  The Go gopher was designed by Renee French.", ebiten.ActualFPS())
+	if g.sim.Mode.Scored {
+		hud += fmt.Sprintf("\nScore: %d\nHiScore: %d", g.state.Score, g.state.HiScore())
+	}
+	if remaining := g.sim.Remaining(); remaining >= 0 {
+		hud += fmt.Sprintf("\nTime: %d", (remaining+sim.TicksPerSecond-1)/sim.TicksPerSecond)
+	}
+	ebitenutil.DebugPrint(screen, hud)
 
+	g.renderer.DrawHomeButton(screen)
 	g.renderer.DrawSpeakerButton(screen, g.state.IsMuted())
+	if !g.state.ShowGameOverDialog {
+		g.drawEvolutionRing(screen)
+	}
+
+	if g.sim.Level != nil {
+		g.drawLevelOverlay(screen)
+		return
+	}
 
 	if g.state.ShowGameOverDialog {
-		g.renderer.DrawGameOverDialog(screen, g.state.FinalScore, g.state.FinalWatermelonHits)
+		g.renderer.DrawGameOverDialog(screen, g.state.FinalScore, g.state.FinalWatermelonHits, g.recorder.Len() > 0)
+	}
 
-		if g.state.GameOverScreenshot == nil {
-			g.state.GameOverScreenshot = ebiten.NewImage(ui.ScreenWidth, ui.ScreenHeight)
-			g.state.GameOverScreenshot.DrawImage(screen, nil)
-			shareGameResultToX(g.state.GameOverScreenshot, g.state.FinalScore, g.state.FinalWatermelonHits)
+	if captureBoard {
+		g.shareResult()
+	}
+}
+
+func (g *Game) modeButtons() []ui.ModeButton {
+	var buttons []ui.ModeButton
+	for _, m := range g.modes() {
+		b := ui.ModeButton{
+			Label:    i18n.T(m.Label),
+			Selected: m.Name == g.sim.Mode.Name,
 		}
+		switch {
+		case m.Name == mode.Puzzle:
+			b.Detail = i18n.Tf("stars_total", g.progress.TotalStars(), len(level.All())*level.MaxStars)
+		case m.Name == mode.Online:
+			b.Detail = i18n.Tf("online_room", roomName())
+		case m.Name == mode.Daily:
+			challenge := daily.Today()
+			if attempt, played := daily.Played(challenge.Date); played {
+				b.Detail = i18n.Tf("daily_played", attempt.Score)
+			} else {
+				b.Detail = i18n.T(challenge.Modifier.Label)
+			}
+		case m.Scored:
+			b.Detail = i18n.Tf("best", g.state.HiScores[m.Name])
+		}
+		buttons = append(buttons, b)
 	}
+	return buttons
+}
+
+func (g *Game) shareResult() {
+	format, withQR := cardOptions()
+	card := ui.ShareCard{
+		Score:          g.state.FinalScore,
+		WatermelonHits: g.state.FinalWatermelonHits,
+		MaxCombo:       g.state.FinalMaxCombo,
+		Seed:           g.sim.Seed,
+		Date:           g.state.FinishedAt,
+		Board:          g.screenshot,
+	}
+	if withQR {
+		card.URL = share.GameURL
+	}
+
+	result := share.NewResult(g.state.FinalScore, g.state.FinalWatermelonHits, g.state.FinalMaxCombo, g.state.FinishedAt)
+	shareGameResultToX(g.renderer.RenderShareCard(card, format), result)
+}
+
+func (g *Game) exportClip() {
+	data, err := g.recorder.EncodeGIF()
+	if err != nil {
+		log.Printf("failed to encode clip: %v", err)
+		return
+	}
+	exportGIF(data, g.state.FinishedAt)
 }
 
 func (g *Game) Layout(outsideWidth, outsideHeight int) (int, int) {
+	if g.match != nil {
+		return versus.Players * ui.ScreenWidth, ui.ScreenHeight
+	}
+	if g.room != nil {
+		return ui.ScreenWidth + onlinePanelWidth, ui.ScreenHeight
+	}
 	return ui.ScreenWidth, ui.ScreenHeight
 }
 
 func (g *Game) resetGame() {
-	g.physicsManager.ScheduleRemoveAllFruits()
-	g.state.Reset()
+	seed := time.Now().UnixNano()
+	switch {
+	case g.sim.Level != nil:
+		seed = g.sim.Level.Seed
+	case g.sim.Mode.Name == mode.Daily:
+		seed = g.challenge.Seed
+	case g.room != nil:
+		seed = g.room.seed
+	}
+	g.sim.Reset(seed)
+	g.screenshot = nil
+	g.replay = replay.New(g.sim.Mode, seed)
+	g.replay.Level = g.sim.Level
+	g.recorder.Reset()
 	hideShareButton()
 
 	if !g.state.IsMuted() {
@@ -231,56 +527,58 @@ func (g *Game) resetGame() {
 	}
 }
 
-func createCollisionHandler(physManager *physics.Manager) func(*cp.Arbiter, *cp.Space, interface{}) bool {
-	return func(arb *cp.Arbiter, space *cp.Space, data interface{}) bool {
-		shape1, shape2 := arb.Shapes()
-
-		kind1, ok1 := shape1.Body().UserData.(assets.Kind)
-		kind2, ok2 := shape2.Body().UserData.(assets.Kind)
-		if !ok1 || !ok2 {
-			return false
-		}
-
-		if kind1 == assets.Watermelon && kind2 == assets.Watermelon {
-			currentGame.state.IncrementWatermelonHits()
-		}
-
-		space.AddPostStepCallback(physics.CreateRemoveShapeCallback(physManager), shape1, nil)
-		space.AddPostStepCallback(physics.CreateRemoveShapeCallback(physManager), shape2, nil)
-
-		currentGame.state.AddScore(kind1.Score())
-
-		if kind1 == assets.Melon || kind1 == assets.Watermelon {
-			sound.PlaySuikaJoin()
-		} else {
-			sound.PlayJoin()
-		}
-
-		hasNext, nextKind := kind1.Next()
-		if !hasNext {
-			return false
-		}
-
-		pos := shape1.Body().Position().Clone()
-		pos.Sub(shape2.Body().Position()).Mult(0.5).Add(shape2.Body().Position())
-		angle := (shape1.Body().Angle() + shape2.Body().Angle()) / 2
+func (g *Game) setTheme(name string) bool {
+	theme, ok := ui.LookupTheme(name)
+	if !ok {
+		return false
+	}
+	g.renderer.SetTheme(theme)
+	if g.skinPalette != nil {
+		g.renderer.SetColorPalette(*g.skinPalette)
+	}
+	return true
+}
 
-		addData := physics.AddShapeData{
-			Kind:  nextKind,
-			Pos:   pos,
-			Angle: angle,
-		}
-		space.AddPostStepCallback(physics.CreateAddShapeCallback(physManager), nextKind, addData)
+func (g *Game) applySkin() {
+	s, err := loadSkin()
+	if err != nil {
+		log.Printf("failed to load skin: %v", err)
+		return
+	}
+	if s == nil {
+		return
+	}
 
-		return false
+	if err := s.Apply(); err != nil {
+		log.Printf("failed to apply skin %q: %v", s.Name, err)
+	}
+	if s.Palette != nil {
+		g.skinPalette = s.Palette
+		g.renderer.SetColorPalette(*s.Palette)
 	}
 }
 
 func main() {
+	flag.Parse()
+	i18n.SetLocale(detectLocale())
 	setupWASMCallbacks()
 
-	game := NewGame()
+	m, ok := lookupMode(preferredMode())
+	if !ok {
+		m = mode.Default()
+	}
+
+	game := NewGame(m)
 	currentGame = game
+	if name := preferredTheme(); name != "" && !game.setTheme(name) {
+		log.Printf("unknown theme %q", name)
+	}
+	game.applySkin()
+	if name := watchedStream(); name != "" {
+		game.watchStream(name)
+	} else if name := publishedStream(); name != "" {
+		game.publishStream(name)
+	}
 
 	ebiten.SetWindowSize(ui.ScreenWidth, ui.ScreenHeight)
 	ebiten.SetWindowTitle("Suika Shaker")
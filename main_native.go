@@ -3,23 +3,160 @@
 package main
 
 import (
+	"flag"
+	"image"
+	"log"
+	"os"
+	"time"
+
 	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/ponyo877/suika-shaker/internal/i18n"
+	"github.com/ponyo877/suika-shaker/internal/replay"
+	"github.com/ponyo877/suika-shaker/internal/share"
+	"github.com/ponyo877/suika-shaker/internal/skin"
+	"github.com/ponyo877/suika-shaker/internal/ui"
+)
+
+var (
+	skinPath  = flag.String("skin", "", "path to a skin pack directory or .zip file")
+	themeName = flag.String("theme", "", "UI theme: light, dark or high-contrast")
+	langTag   = flag.String("lang", "", "UI language (en or ja); defaults to the environment locale")
+	modeName  = flag.String("mode", "classic", "game mode preselected on the title screen: classic, time-attack, zen, endless or daily")
+	serverURL = flag.String("server", "", "base URL of the server that hosts the daily leaderboard and online rooms")
+	name      = flag.String("name", os.Getenv("USER"), "player name shown on the daily leaderboard and in online rooms")
+	room      = flag.String("room", "lobby", "online room to join on -server")
+	stream    = flag.String("stream", "", "publish every round to this spectator stream on -server")
+	watch     = flag.String("watch", "", "watch this spectator stream on -server instead of playing")
+
+	exportDir       = flag.String("export-dir", "", "directory game-over screenshots, summaries and replays are saved to; empty disables export")
+	copyToClipboard = flag.Bool("clipboard", false, "also copy the game-over screenshot to the clipboard")
+	cardFormat      = flag.String("card-format", "landscape", "share card aspect: landscape, square or portrait")
+	cardQR          = flag.Bool("card-qr", true, "include a QR code to the game URL on the share card")
 )
 
 func setupWASMCallbacks() {
 	// No-op for native builds
 }
 
+func loadSkin() (*skin.Skin, error) {
+	if *skinPath == "" {
+		return nil, nil
+	}
+	return skin.LoadPath(*skinPath)
+}
+
+func detectLocale() i18n.Locale {
+	if *langTag != "" {
+		return i18n.ParseLocale(*langTag)
+	}
+	return i18n.DetectFromEnv()
+}
+
+func preferredTheme() string {
+	return *themeName
+}
+
+func preferredMode() string {
+	return *modeName
+}
+
+func leaderboardURL() string {
+	return *serverURL
+}
+
+func roomName() string {
+	return *room
+}
+
+func publishedStream() string {
+	return *stream
+}
+
+func watchedStream() string {
+	return *watch
+}
+
+func playerName() string {
+	if *name == "" {
+		return "guest"
+	}
+	return *name
+}
+
 func getAcceleration() (float64, float64, float64) {
 	// Return zero acceleration for native builds
 	return 0, 0, 0
 }
 
-func shareGameResultToX(screenshot *ebiten.Image, score int, watermelonHits int) {
-	// No-op for native builds
-	// Could potentially save screenshot to file or open system share dialog
+func cardOptions() (ui.CardFormat, bool) {
+	format, ok := ui.LookupCardFormat(*cardFormat)
+	if !ok {
+		format = ui.CardLandscape
+	}
+	return format, *cardQR
+}
+
+// shareGameResultToX is called while drawing, so it only copies the card's
+// pixels there and leaves encoding and writing the files to a goroutine.
+func shareGameResultToX(card *ebiten.Image, result share.Result) {
+	if card == nil || *exportDir == "" {
+		return
+	}
+	img := image.NewRGBA(card.Bounds())
+	card.ReadPixels(img.Pix)
+	go exportResult(*exportDir, img, result)
+}
+
+func exportResult(dir string, img image.Image, result share.Result) {
+	pngData, err := share.EncodePNG(img)
+	if err != nil {
+		log.Printf("failed to encode screenshot: %v", err)
+		return
+	}
+
+	path, err := share.Export(dir, pngData, result)
+	if err != nil {
+		log.Printf("failed to export game result: %v", err)
+		return
+	}
+	log.Printf("Saved game result to %s", path)
+
+	if *copyToClipboard {
+		if err := share.CopyImageToClipboard(path); err != nil {
+			log.Printf("failed to copy screenshot to clipboard: %v", err)
+		}
+	}
+}
+
+func exportGIF(data []byte, playedAt time.Time) {
+	dir := *exportDir
+	if dir == "" {
+		dir = "."
+	}
+	path, err := share.ExportGIF(dir, data, playedAt)
+	if err != nil {
+		log.Printf("failed to export clip: %v", err)
+		return
+	}
+	log.Printf("Saved clip to %s", path)
+}
+
+func exportReplay(r *replay.Replay, playedAt time.Time) {
+	if *exportDir == "" {
+		return
+	}
+	path, err := share.ExportReplay(*exportDir, r, playedAt)
+	if err != nil {
+		log.Printf("failed to export replay: %v", err)
+		return
+	}
+	log.Printf("Saved replay to %s", path)
 }
 
 func hideShareButton() {
 	// No-op for native builds
 }
+
+func showTitleScreen() {
+	// No-op for native builds
+}
@@ -5,12 +5,16 @@ package main
 import (
 	"bytes"
 	"encoding/base64"
-	"fmt"
-	"image/png"
 	"syscall/js"
+	"time"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/ponyo877/suika-shaker/assets/sound"
+	"github.com/ponyo877/suika-shaker/internal/i18n"
+	"github.com/ponyo877/suika-shaker/internal/replay"
+	"github.com/ponyo877/suika-shaker/internal/share"
+	"github.com/ponyo877/suika-shaker/internal/skin"
+	"github.com/ponyo877/suika-shaker/internal/ui"
 )
 
 type AccelerationData struct {
@@ -23,6 +27,23 @@ func setupWASMCallbacks() {
 	js.Global().Set("setAcceleration", js.FuncOf(setAccelerationCallback))
 	js.Global().Set("startGameFromJS", js.FuncOf(startGameCallback))
 	js.Global().Set("startAudioContext", js.FuncOf(startAudioCallback))
+	js.Global().Set("setTheme", js.FuncOf(setThemeCallback))
+	js.Global().Set("translate", js.FuncOf(translateCallback))
+	js.Global().Set("getLastReplay", js.FuncOf(getLastReplayCallback))
+}
+
+func translateCallback(this js.Value, args []js.Value) interface{} {
+	if len(args) < 1 {
+		return ""
+	}
+	return i18n.T(args[0].String())
+}
+
+func setThemeCallback(this js.Value, args []js.Value) interface{} {
+	if currentGame != nil && len(args) >= 1 {
+		return currentGame.setTheme(args[0].String())
+	}
+	return false
 }
 
 func setAccelerationCallback(this js.Value, args []js.Value) interface{} {
@@ -35,8 +56,7 @@ func setAccelerationCallback(this js.Value, args []js.Value) interface{} {
 }
 
 func startGameCallback(this js.Value, args []js.Value) interface{} {
-	if currentGame != nil {
-		currentGame.state.ShowTitleScreen = false
+	if currentGame != nil && currentGame.startGame() {
 		if js.Global().Get("onGameStarted").Truthy() {
 			js.Global().Call("onGameStarted")
 		}
@@ -51,33 +71,134 @@ func startAudioCallback(this js.Value, args []js.Value) interface{} {
 	return nil
 }
 
+func queryParam(name string) string {
+	v := js.Global().Get("URLSearchParams").New(js.Global().Get("location").Get("search")).Call("get", name)
+	if v.IsNull() {
+		return ""
+	}
+	return v.String()
+}
+
+func detectLocale() i18n.Locale {
+	if lang := queryParam("lang"); lang != "" {
+		return i18n.ParseLocale(lang)
+	}
+	if lang := js.Global().Get("navigator").Get("language"); lang.Type() == js.TypeString {
+		return i18n.ParseLocale(lang.String())
+	}
+	return i18n.DefaultLocale
+}
+
+func preferredTheme() string {
+	if name := queryParam("theme"); name != "" {
+		return name
+	}
+	matchMedia := js.Global().Get("matchMedia")
+	if matchMedia.Truthy() && js.Global().Call("matchMedia", "(prefers-color-scheme: dark)").Get("matches").Bool() {
+		return ui.ThemeDark
+	}
+	return ""
+}
+
+func preferredMode() string {
+	return queryParam("mode")
+}
+
+func leaderboardURL() string {
+	return js.Global().Get("location").Get("origin").String()
+}
+
+func roomName() string {
+	if room := queryParam("room"); room != "" {
+		return room
+	}
+	return "lobby"
+}
+
+func publishedStream() string {
+	return queryParam("stream")
+}
+
+func watchedStream() string {
+	return queryParam("watch")
+}
+
+func playerName() string {
+	if name := queryParam("name"); name != "" {
+		return name
+	}
+	return "guest"
+}
+
+func showTitleScreen() {
+	if js.Global().Get("onTitleScreen").Truthy() {
+		js.Global().Call("onTitleScreen")
+	}
+}
+
+func loadSkin() (*skin.Skin, error) {
+	url := queryParam("skin")
+	if url == "" {
+		if v := js.Global().Get("skinURL"); v.Type() == js.TypeString {
+			url = v.String()
+		}
+	}
+	if url == "" {
+		return nil, nil
+	}
+	return skin.LoadURL(url)
+}
+
 func getAcceleration() (float64, float64, float64) {
 	return accelData.X, accelData.Y, accelData.Z
 }
 
-func shareGameResultToX(screenshot *ebiten.Image, score, watermelonHits int) {
-	if screenshot == nil {
+func cardOptions() (ui.CardFormat, bool) {
+	format, ok := ui.LookupCardFormat(queryParam("card"))
+	if !ok {
+		format = ui.CardLandscape
+	}
+	return format, queryParam("qr") != "0"
+}
+
+func shareGameResultToX(card *ebiten.Image, result share.Result) {
+	if card == nil {
 		return
 	}
 
-	var buf bytes.Buffer
-	if err := png.Encode(&buf, screenshot); err != nil {
+	pngData, err := share.EncodePNG(card)
+	if err != nil {
 		return
 	}
 
-	base64Image := base64.StdEncoding.EncodeToString(buf.Bytes())
-	shareText := fmt.Sprintf(
-		"Suika Shaker\nScore: %d\nWatermelon Hits: %d\n%s",
-		score,
-		watermelonHits,
-		"https://ponyo877.github.io/suika-shaker/",
-	)
+	base64Image := base64.StdEncoding.EncodeToString(pngData)
+	shareText := result.ShareText
 
 	if js.Global().Get("showShareButton").Truthy() {
 		js.Global().Call("showShareButton", base64Image, shareText)
 	}
 }
 
+func exportGIF(data []byte, playedAt time.Time) {
+	if js.Global().Get("saveGIF").Truthy() {
+		js.Global().Call("saveGIF", base64.StdEncoding.EncodeToString(data), share.BaseName(playedAt)+".gif")
+	}
+}
+
+var lastReplay string
+
+func exportReplay(r *replay.Replay, playedAt time.Time) {
+	var buf bytes.Buffer
+	if err := r.Write(&buf); err != nil {
+		return
+	}
+	lastReplay = buf.String()
+}
+
+func getLastReplayCallback(this js.Value, args []js.Value) interface{} {
+	return lastReplay
+}
+
 func hideShareButton() {
 	if js.Global().Get("hideShareButton").Truthy() {
 		js.Global().Call("hideShareButton")
@@ -0,0 +1,318 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"log"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/ponyo877/suika-shaker/assets/sound"
+	"github.com/ponyo877/suika-shaker/internal/i18n"
+	"github.com/ponyo877/suika-shaker/internal/online"
+	"github.com/ponyo877/suika-shaker/internal/physics"
+	"github.com/ponyo877/suika-shaker/internal/sim"
+	"github.com/ponyo877/suika-shaker/internal/snapshot"
+	"github.com/ponyo877/suika-shaker/internal/ui"
+)
+
+// The side panel shows every opponent's latest board at thumbnail size.
+const (
+	onlinePanelWidth = 240
+	thumbScale       = 0.15
+	thumbColumns     = 3
+	thumbGap         = 6
+	thumbTop         = 40
+	thumbRowHeight   = 160
+	thumbLabelHeight = 32
+)
+
+// onlineRoom is the client's view of an online room: who is in it, their
+// latest boards, and when the current round starts.
+type onlineRoom struct {
+	client  *online.Client
+	name    string
+	self    int
+	state   string
+	players []online.Player
+	boards  map[int]snapshot.Snapshot
+	seed    int64
+	startAt time.Time // zero while in the lobby
+	results []online.Player
+	status  string
+	thumb   *ebiten.Image
+}
+
+// joinRoom connects to the configured room and widens the window for the
+// opponents panel.
+func (g *Game) joinRoom() bool {
+	name := roomName()
+	url, err := online.RoomURL(leaderboardURL(), name, playerName())
+	if err != nil {
+		log.Printf("failed to join room: %v", err)
+		return false
+	}
+
+	g.room = &onlineRoom{
+		client: online.Connect(url),
+		name:   name,
+		boards: map[int]snapshot.Snapshot{},
+		status: i18n.T("online_connecting"),
+		thumb:  ebiten.NewImage(ui.ScreenWidth, ui.ScreenHeight),
+	}
+	g.state.ShowTitleScreen = false
+	ebiten.SetWindowSize(ui.ScreenWidth+onlinePanelWidth, ui.ScreenHeight)
+	hideShareButton()
+	return true
+}
+
+func (g *Game) leaveRoom() {
+	g.room.client.Close()
+	g.room = nil
+	ebiten.SetWindowSize(ui.ScreenWidth, ui.ScreenHeight)
+}
+
+func (g *Game) updateRoom() {
+	r := g.room
+	for _, m := range r.client.Poll() {
+		g.handleRoomMessage(m)
+	}
+	if err := r.client.Err(); err != nil && r.status != i18n.T("online_disconnected") {
+		log.Printf("online room: %v", err)
+		r.status = i18n.T("online_disconnected")
+	}
+
+	if g.inputHandler.CheckThemeToggle() {
+		g.setTheme(ui.NextThemeName(g.renderer.Theme().Name))
+	}
+	if clicked, x, y := g.inputHandler.CheckMouseClick(); clicked {
+		g.handleRoomClick(x, y)
+	}
+	for _, touch := range g.inputHandler.CheckTouchInput() {
+		g.handleRoomClick(touch.X, touch.Y)
+	}
+	if g.room == nil {
+		return
+	}
+
+	if r.startAt.IsZero() {
+		if g.inputHandler.CheckStartKey() {
+			r.client.Send(online.Message{Type: online.TypeStart})
+		}
+		return
+	}
+	if time.Now().Before(r.startAt) || g.state.ShowGameOverDialog {
+		return
+	}
+
+	g.stepGame()
+	if g.state.Count%online.ProgressInterval == 0 && !g.state.GameOver {
+		g.sendProgress()
+	}
+}
+
+func (g *Game) handleRoomMessage(m online.Message) {
+	r := g.room
+	switch m.Type {
+	case online.TypeWelcome:
+		r.self = m.Player
+		r.status = ""
+	case online.TypeRoom:
+		r.state, r.players = m.State, m.Players
+	case online.TypeStart:
+		g.startRound(m)
+	case online.TypeProgress:
+		if m.Board != nil {
+			r.boards[m.Player] = *m.Board
+		}
+	case online.TypeEnd:
+		// The server's clock decides when the round is over, even if ours
+		// has not run out yet.
+		if !r.startAt.IsZero() && !g.state.GameOver {
+			g.state.TriggerGameOver()
+		}
+	case online.TypeResults:
+		r.results = m.Players
+	case online.TypeError:
+		r.status = m.Error
+	}
+}
+
+// startRound resets the board to the server's seed; play begins once the
+// countdown to startAt has run out.
+func (g *Game) startRound(m online.Message) {
+	r := g.room
+	r.seed = m.Seed
+	r.startAt = time.Now().Add(time.Duration(m.StartIn) * time.Millisecond)
+	r.results = nil
+	r.status = ""
+	clear(r.boards)
+
+	g.selectMode(online.Mode(time.Duration(m.Duration) * time.Second))
+	g.resetGame()
+}
+
+func (g *Game) sendProgress() {
+	snap := g.sim.Snapshot()
+	g.room.client.Send(online.Message{Type: online.TypeProgress, Board: &snap})
+}
+
+// finishRound reports the final board and score, with the replay the server
+// checks it against.
+func (g *Game) finishRound() {
+	g.sendProgress()
+
+	var buf bytes.Buffer
+	if err := g.replay.Write(&buf); err != nil {
+		log.Printf("failed to encode replay: %v", err)
+		return
+	}
+	g.room.client.Send(online.Message{
+		Type:   online.TypeResult,
+		Score:  g.state.FinalScore,
+		Replay: buf.Bytes(),
+	})
+}
+
+func (g *Game) handleRoomClick(x, y int) {
+	r := g.room
+	if x >= ui.ScreenWidth {
+		return
+	}
+
+	if g.inputHandler.IsButtonClicked(x, y, ui.HomeButtonConfig) {
+		if !r.startAt.IsZero() {
+			g.leaveRound()
+		}
+		g.returnToTitle()
+		return
+	}
+
+	if r.startAt.IsZero() {
+		if r.state == online.Waiting && g.inputHandler.IsButtonClicked(x, y, ui.StartButtonConfig) {
+			r.client.Send(online.Message{Type: online.TypeStart})
+		}
+		return
+	}
+
+	if g.inputHandler.IsButtonClicked(x, y, ui.SpeakerButtonConfig) {
+		g.state.SetMuted(!g.state.IsMuted())
+		sound.SetMuted(g.state.IsMuted())
+	}
+
+	if !g.state.ShowGameOverDialog || r.results == nil {
+		return
+	}
+	dialog := g.renderer.Theme().Dialog
+	if g.inputHandler.IsRetryButtonClicked(x, y, dialog) {
+		// Wait in the lobby until the server starts the next round.
+		r.startAt = time.Time{}
+		sound.StopBackgroundMusic()
+		r.client.Send(online.Message{Type: online.TypeStart})
+	} else if g.inputHandler.IsButtonClicked(x, y, dialog.NextButton()) {
+		g.returnToTitle()
+	}
+}
+
+func (g *Game) drawRoom(screen *ebiten.Image) {
+	screen.Fill(g.renderer.Theme().Colors.Black)
+	board := screen.SubImage(image.Rect(0, 0, ui.ScreenWidth, ui.ScreenHeight)).(*ebiten.Image)
+	if g.room.startAt.IsZero() {
+		g.drawLobby(board)
+	} else {
+		g.drawRound(board)
+	}
+	g.drawOpponents(screen)
+}
+
+func (g *Game) drawLobby(screen *ebiten.Image) {
+	r := g.room
+	var names []string
+	for _, p := range r.players {
+		if p.ID == r.self {
+			names = append(names, i18n.Tf("online_you", p.Name))
+		} else {
+			names = append(names, p.Name)
+		}
+	}
+
+	status := r.status
+	if status == "" {
+		status = i18n.Tf("online_waiting", len(r.players), online.MaxPlayers)
+	}
+	g.renderer.DrawLobby(screen, i18n.Tf("online_room", r.name), names, status, physics.PaddingBottom)
+	if r.state == online.Waiting && r.client.Err() == nil {
+		g.renderer.DrawStartButton(screen)
+	}
+	g.renderer.DrawHomeButton(screen)
+}
+
+func (g *Game) drawRound(screen *ebiten.Image) {
+	r := g.room
+	g.renderer.DrawBoard(screen, g.sim)
+
+	hud := i18n.Tf("hud_score", g.state.Score)
+	if remaining := g.sim.Remaining(); remaining >= 0 {
+		hud += "\n" + i18n.Tf("hud_time", (remaining+sim.TicksPerSecond-1)/sim.TicksPerSecond)
+	}
+	g.renderer.DrawHUD(screen, hud, 4, 4)
+	g.renderer.DrawHomeButton(screen)
+	g.renderer.DrawSpeakerButton(screen, g.state.IsMuted())
+
+	if wait := time.Until(r.startAt); wait > 0 {
+		g.renderer.DrawCountdown(screen, fmt.Sprintf("%d", int(wait.Seconds())+1))
+		return
+	}
+	if !g.state.ShowGameOverDialog {
+		return
+	}
+
+	title, detail := i18n.T("online_finished"), i18n.T("online_waiting_results")
+	for i, p := range r.results {
+		if p.ID != r.self {
+			continue
+		}
+		title = i18n.Tf("online_place", i+1, len(r.results))
+		detail = i18n.Tf("online_score", p.Score)
+		if p.Rejected {
+			detail = i18n.T("online_rejected")
+		}
+	}
+	g.renderer.DrawResultDialog(screen, title, detail, -1, i18n.T("retry"), i18n.T("menu"))
+}
+
+// drawOpponents fills the side panel with each opponent's name, score and
+// most recent board.
+func (g *Game) drawOpponents(screen *ebiten.Image) {
+	r := g.room
+	g.renderer.DrawHUD(screen, i18n.Tf("online_room", r.name), ui.ScreenWidth+thumbGap, 10)
+
+	thumbWidth := int(ui.ScreenWidth * thumbScale)
+	i := 0
+	for _, p := range r.players {
+		if p.ID == r.self {
+			continue
+		}
+		x := ui.ScreenWidth + thumbGap + (i%thumbColumns)*(thumbWidth+thumbGap)
+		y := thumbTop + (i/thumbColumns)*thumbRowHeight
+		i++
+
+		snap, ok := r.boards[p.ID]
+		score := p.Score
+		if ok {
+			score = max(score, snap.Score)
+		}
+		g.renderer.DrawHUD(screen, i18n.Tf("online_opponent", p.Name, score), x, y)
+		if !ok {
+			continue
+		}
+
+		g.renderer.DrawSnapshot(r.thumb, snap, physics.PaddingBottom)
+		op := &ebiten.DrawImageOptions{}
+		op.Filter = ebiten.FilterLinear
+		op.GeoM.Scale(thumbScale, thumbScale)
+		op.GeoM.Translate(float64(x), float64(y+thumbLabelHeight))
+		screen.DrawImage(r.thumb, op)
+	}
+}
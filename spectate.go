@@ -0,0 +1,102 @@
+package main
+
+import (
+	"log"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/ponyo877/suika-shaker/internal/i18n"
+	"github.com/ponyo877/suika-shaker/internal/online"
+	"github.com/ponyo877/suika-shaker/internal/physics"
+	"github.com/ponyo877/suika-shaker/internal/snapshot"
+	"github.com/ponyo877/suika-shaker/internal/ui"
+)
+
+// reconnectDelay is how long a spectator waits before trying a dropped
+// stream again, so a screen left showing a tournament recovers by itself.
+const reconnectDelay = 3 * time.Second
+
+// spectator follows someone else's stream instead of playing.
+type spectator struct {
+	url     string
+	name    string
+	client  *online.Client
+	frame   snapshot.Snapshot
+	live    bool
+	retryAt time.Time
+}
+
+// publishStream starts sending every tick of this player's board to the
+// named stream on the server.
+func (g *Game) publishStream(name string) {
+	url, err := online.PublishURL(leaderboardURL(), name)
+	if err != nil {
+		log.Printf("failed to publish stream: %v", err)
+		return
+	}
+	g.stream = online.Connect(url)
+}
+
+func (g *Game) sendFrame() {
+	if err := g.stream.Err(); err != nil {
+		log.Printf("stream stopped: %v", err)
+		g.stream = nil
+		return
+	}
+	snap := g.sim.Snapshot()
+	g.stream.Send(online.Message{Type: online.TypeFrame, Board: &snap})
+}
+
+// watchStream turns the game into a viewer for the named stream.
+func (g *Game) watchStream(name string) {
+	url, err := online.WatchURL(leaderboardURL(), name)
+	if err != nil {
+		log.Printf("failed to watch stream: %v", err)
+		return
+	}
+	g.watch = &spectator{url: url, name: name, client: online.Connect(url)}
+	g.state.ShowTitleScreen = false
+}
+
+func (g *Game) updateWatch() {
+	w := g.watch
+	for _, m := range w.client.Poll() {
+		switch m.Type {
+		case online.TypeFrame:
+			if m.Board != nil {
+				w.frame, w.live = *m.Board, true
+			}
+		case online.TypeEnd:
+			w.live = false
+		}
+	}
+
+	if err := w.client.Err(); err != nil {
+		if w.retryAt.IsZero() {
+			log.Printf("stream %s: %v", w.name, err)
+			w.live = false
+			w.retryAt = time.Now().Add(reconnectDelay)
+		} else if time.Now().After(w.retryAt) {
+			w.client = online.Connect(w.url)
+			w.retryAt = time.Time{}
+		}
+	}
+
+	if g.inputHandler.CheckThemeToggle() {
+		g.setTheme(ui.NextThemeName(g.renderer.Theme().Name))
+	}
+}
+
+func (g *Game) drawWatch(screen *ebiten.Image) {
+	w := g.watch
+	g.renderer.DrawSnapshot(screen, w.frame, physics.PaddingBottom)
+
+	status := i18n.T("watch_live")
+	switch {
+	case !w.live:
+		status = i18n.T("watch_waiting")
+	case w.frame.Over:
+		status = i18n.T("watch_over")
+	}
+	g.renderer.DrawHUD(screen, i18n.Tf("watch_status", w.name, status, w.frame.Score), 4, 4)
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	assets "github.com/ponyo877/suika-shaker/assets/image"
+	"github.com/ponyo877/suika-shaker/assets/sound"
+	"github.com/ponyo877/suika-shaker/internal/i18n"
+	"github.com/ponyo877/suika-shaker/internal/ui"
+	"github.com/ponyo877/suika-shaker/internal/versus"
+)
+
+var versusHints = [versus.Players]string{"versus_hint_p1", "versus_hint_p2"}
+
+// startMatch replaces the single board with two side by side; the window is
+// widened so each keeps its usual size.
+func (g *Game) startMatch() {
+	g.match = versus.NewMatch(time.Now().UnixNano())
+	g.match.OnMerge = func(_ int, kind assets.Kind) { playMergeSound(kind) }
+	g.match.OnDanger = func(int) { sound.PlayWarning() }
+	for i := range g.boards {
+		if g.boards[i] == nil {
+			g.boards[i] = ebiten.NewImage(ui.ScreenWidth, ui.ScreenHeight)
+		}
+	}
+
+	g.state.ShowTitleScreen = false
+	ebiten.SetWindowSize(versus.Players*ui.ScreenWidth, ui.ScreenHeight)
+	hideShareButton()
+	if !g.state.IsMuted() {
+		sound.StartBackgroundMusic()
+	}
+}
+
+func (g *Game) updateMatch() {
+	if g.inputHandler.CheckThemeToggle() {
+		g.setTheme(ui.NextThemeName(g.renderer.Theme().Name))
+	}
+
+	if clicked, x, y := g.inputHandler.CheckMouseClick(); clicked {
+		g.handleMatchClick(x, y)
+	}
+	for _, touch := range g.inputHandler.CheckTouchInput() {
+		g.handleMatchClick(touch.X, touch.Y)
+	}
+	if g.match == nil {
+		return
+	}
+
+	if g.match.Over() {
+		if g.inputHandler.CheckStartKey() {
+			g.startMatch()
+		}
+		return
+	}
+
+	var controls [versus.Players]versus.Control
+	for i := range controls {
+		controls[i].Tilt, controls[i].Shake = g.inputHandler.VersusControl(i)
+	}
+	g.match.Step(controls)
+
+	if g.match.Over() {
+		sound.PlayGameOver()
+		sound.StopBackgroundMusic()
+	}
+}
+
+// handleMatchClick maps a click on either half of the screen onto that
+// board's buttons; both boards offer the same ones.
+func (g *Game) handleMatchClick(x, y int) {
+	x %= ui.ScreenWidth
+
+	if g.inputHandler.IsButtonClicked(x, y, ui.SpeakerButtonConfig) {
+		g.state.SetMuted(!g.state.IsMuted())
+		sound.SetMuted(g.state.IsMuted())
+	}
+
+	if g.inputHandler.IsButtonClicked(x, y, ui.HomeButtonConfig) {
+		g.returnToTitle()
+		return
+	}
+
+	if !g.match.Over() {
+		return
+	}
+	dialog := g.renderer.Theme().Dialog
+	if g.inputHandler.IsRetryButtonClicked(x, y, dialog) {
+		g.startMatch()
+	} else if g.inputHandler.IsButtonClicked(x, y, dialog.NextButton()) {
+		g.returnToTitle()
+	}
+}
+
+func (g *Game) drawMatch(screen *ebiten.Image) {
+	for i, board := range g.match.Boards {
+		img := g.boards[i]
+		g.renderer.DrawBoard(img, board)
+
+		g.renderer.DrawHUD(img, i18n.Tf("versus_hud",
+			i+1, board.State.Score, g.match.Sent[i], board.PendingGarbage()), 4, 4)
+		g.renderer.DrawHUD(img, i18n.T(versusHints[i]), 10, ui.ScreenHeight-24)
+		g.renderer.DrawHomeButton(img)
+		g.renderer.DrawSpeakerButton(img, g.state.IsMuted())
+
+		if g.match.Over() {
+			title := i18n.T("versus_lose")
+			switch g.match.Winner {
+			case i:
+				title = i18n.T("versus_win")
+			case versus.Draw:
+				title = i18n.T("versus_draw")
+			}
+			detail := i18n.Tf("player_score", i+1, board.State.FinalScore)
+			g.renderer.DrawResultDialog(img, title, detail, -1, i18n.T("retry"), i18n.T("menu"))
+		}
+
+		op := &ebiten.DrawImageOptions{}
+		op.GeoM.Translate(float64(i*ui.ScreenWidth), 0)
+		screen.DrawImage(img, op)
+	}
+}